@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/mpetavy/common"
+)
+
+// artifactsFile is the manifest of the files generated into the output directory, which the clean
+// subcommand removes
+const artifactsFile = ".goja_go.json"
+
+const artifactsVersion = 1
+
+type Artifacts struct {
+	Version int      `json:"version"`
+	Files   []string `json:"files"`
+}
+
+var (
+	artifactsMu sync.Mutex
+	artifacts   []string
+)
+
+// trackArtifact records the written file for the manifest of the output directory
+func trackArtifact(filename string) {
+	artifactsMu.Lock()
+	defer artifactsMu.Unlock()
+
+	artifacts = append(artifacts, filename)
+}
+
+func artifactsFilename() (string, error) {
+	dir, err := filepath.Abs(*output)
+	if common.Error(err) {
+		return "", err
+	}
+
+	return filepath.Join(dir, artifactsFile), nil
+}
+
+func readArtifacts(filename string) (*Artifacts, error) {
+	a := &Artifacts{Version: artifactsVersion}
+
+	ba, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+
+	if common.Error(err) {
+		return nil, err
+	}
+
+	err = json.Unmarshal(ba, a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifacts manifest %s: %w", filename, err)
+	}
+
+	return a, nil
+}
+
+// saveArtifacts adds the files written into the output directory since the last call to its
+// manifest, dropping the files which do not exist anymore
+func saveArtifacts() error {
+	artifactsMu.Lock()
+	written := artifacts
+	artifacts = nil
+	artifactsMu.Unlock()
+
+	if *output == stdout || len(written) == 0 {
+		return nil
+	}
+
+	filename, err := artifactsFilename()
+	if common.Error(err) {
+		return err
+	}
+
+	a, err := readArtifacts(filename)
+	if common.Error(err) {
+		return err
+	}
+
+	for _, path := range written {
+		rel, ok := relPath(filepath.Dir(filename), path)
+		if ok && !slices.Contains(a.Files, filepath.ToSlash(rel)) {
+			a.Files = append(a.Files, filepath.ToSlash(rel))
+		}
+	}
+
+	a.Files = slices.DeleteFunc(a.Files, func(rel string) bool {
+		return !common.FileExists(filepath.Join(filepath.Dir(filename), filepath.FromSlash(rel)))
+	})
+
+	sort.Strings(a.Files)
+
+	ba, err := json.MarshalIndent(a, "", "  ")
+	if common.Error(err) {
+		return err
+	}
+
+	return os.WriteFile(filename, append(ba, '\n'), common.DefaultFileMode)
+}
+
+// cleanArtifacts removes the files listed by the manifest of the output directory, the
+// directories left empty by that and the manifest itself
+func cleanArtifacts() error {
+	filename, err := artifactsFilename()
+	if common.Error(err) {
+		return err
+	}
+
+	if !common.FileExists(filename) {
+		common.Info("nothing to clean, %s does not exist", filename)
+
+		return nil
+	}
+
+	a, err := readArtifacts(filename)
+	if common.Error(err) {
+		return err
+	}
+
+	root := filepath.Dir(filename)
+	dirs := []string{}
+
+	for _, rel := range a.Files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+
+		// never remove anything outside of the output directory
+		if _, ok := relPath(root, path); !ok {
+			return fmt.Errorf("invalid path %s in artifacts manifest %s", rel, filename)
+		}
+
+		if *dryRun {
+			fmt.Printf("%s (not removed)\n", path)
+
+			continue
+		}
+
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		fmt.Printf("%s\n", path)
+
+		for dir := filepath.Dir(path); dir != root && !slices.Contains(dirs, dir); dir = filepath.Dir(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if *dryRun {
+		return nil
+	}
+
+	// the deepest directories first, so their parents may become empty
+	sort.Slice(dirs, func(i, j int) bool {
+		return len(dirs[i]) > len(dirs[j])
+	})
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err == nil && len(entries) == 0 {
+			err = os.Remove(dir)
+			if common.Error(err) {
+				return err
+			}
+		}
+	}
+
+	return os.Remove(filename)
+}
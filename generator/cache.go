@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/mpetavy/common"
+)
+
+// cacheVersion is part of the cache keys and is to be incremented when the cached data changes
+const cacheVersion = 4
+
+// uncachedFlags do not change the scan of a package
+var uncachedFlags = []string{"cache", "dry-run", "n", "o", "parallel", "watch"}
+
+var executableHash = sync.OnceValue(func() string {
+	h := sha256.New()
+
+	path, err := os.Executable()
+	if err == nil {
+		hashFile(h, path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+})
+
+// cacheEntry is a scanned package with the unexported data of the scan used for writing it and
+// for repeating its warnings
+type cacheEntry struct {
+	Data        *Data
+	PkgPath     string
+	Deps        []string
+	ImportNames map[string]string
+	Symbols     []string
+	Warnings    []string
+}
+
+func hashFile(h hash.Hash, filename string) {
+	f, err := os.Open(filename)
+	if err != nil {
+		fmt.Fprintf(h, "%s -\n", filename)
+
+		return
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fmt.Fprintf(h, "%s\n", filename)
+	_, _ = io.Copy(h, f)
+}
+
+// inputKey returns the key of the package name scanned from filenames in the given version,
+// covering the generator, the go.mod, the build context, the flags and the filters
+func inputKey(name string, version string, filenames []string) string {
+	ctx := buildContext()
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%d %s %s %s@%s\n", cacheVersion, executableHash(), runtime.Version(), name, version)
+	fmt.Fprintf(h, "%s %s %v\n", ctx.GOOS, ctx.GOARCH, ctx.BuildTags)
+
+	if *gomodFile != "" {
+		hashFile(h, filepath.Join(filepath.Dir(*gomodFile), "go.mod"))
+		hashFile(h, filepath.Join(filepath.Dir(*gomodFile), "go.sum"))
+	}
+
+	for _, filename := range filenames {
+		hashFile(h, filename)
+	}
+
+	flag.VisitAll(func(fl *flag.Flag) {
+		if !slices.Contains(uncachedFlags, fl.Name) {
+			fmt.Fprintf(h, "-%s=%s\n", fl.Name, fl.Value.String())
+		}
+	})
+
+	for _, list := range [][]string{configIncludes, configExcludes, sortedKeys(manifestSymbols), sortedKeys(verifyExcluded)} {
+		fmt.Fprintf(h, "%q\n", list)
+	}
+
+	for _, name := range sortedKeys(renames) {
+		fmt.Fprintf(h, "%s=%s\n", name, renames[name])
+	}
+
+	for _, name := range sortedKeys(configProperties) {
+		fmt.Fprintf(h, "%s=%t\n", name, configProperties[name])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func cacheFile(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "goja_go", key+".gob"), nil
+}
+
+// loadCache returns the package scanned with the key by an earlier run, nil if not cached
+func loadCache(key string) *Data {
+	if !*cache {
+		return nil
+	}
+
+	filename, err := cacheFile(key)
+	if common.DebugError(err) {
+		return nil
+	}
+
+	ba, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	entry := cacheEntry{}
+
+	err = gob.NewDecoder(bytes.NewReader(ba)).Decode(&entry)
+	if common.DebugError(err) {
+		return nil
+	}
+
+	data := entry.Data
+	data.pkgPath = entry.PkgPath
+	data.deps = entry.Deps
+	data.importNames = entry.ImportNames
+	data.symbols = entry.Symbols
+	data.warnings = entry.Warnings
+
+	if data.Helpers == nil {
+		data.Helpers = make(map[string]bool)
+	}
+
+	return data
+}
+
+// saveCache stores the scanned package data with the key, failing silently as the cache is optional
+func saveCache(key string, data *Data) {
+	if !*cache {
+		return
+	}
+
+	filename, err := cacheFile(key)
+	if common.DebugError(err) {
+		return
+	}
+
+	var buffer bytes.Buffer
+
+	err = gob.NewEncoder(&buffer).Encode(cacheEntry{
+		Data:        data,
+		PkgPath:     data.pkgPath,
+		Deps:        data.deps,
+		ImportNames: data.importNames,
+		Symbols:     data.symbols,
+		Warnings:    data.warnings,
+	})
+	if common.DebugError(err) {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(filename), common.DefaultDirMode)
+	if common.DebugError(err) {
+		return
+	}
+
+	common.DebugError(os.WriteFile(filename, buffer.Bytes(), common.DefaultFileMode))
+}
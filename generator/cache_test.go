@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// captureStderr returns what fn writes to stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := os.Stderr
+	os.Stderr = f
+
+	defer func() {
+		os.Stderr = stderr
+	}()
+
+	fn()
+
+	ba, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(ba)
+}
+
+func TestCacheRestoresSymbolsAndDiagnostics(t *testing.T) {
+	// os.UserCacheDir of the platforms
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("LocalAppData", t.TempDir())
+
+	manifest := filepath.Join(t.TempDir(), "manifest.txt")
+	manifests := []string{}
+	diagnostics := []string{}
+
+	for i := 0; i < 2; i++ {
+		stderr := captureStderr(t, func() {
+			err := runTestdata(t, "generics", "-cache", "-format", "json", "-write-manifest", manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		ba, err := os.ReadFile(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		manifests = append(manifests, string(ba))
+
+		lines := slices.DeleteFunc(strings.Split(stderr, "\n"), func(line string) bool {
+			return !strings.HasPrefix(line, `{"level":"skip"`)
+		})
+
+		diagnostics = append(diagnostics, strings.Join(lines, "\n"))
+	}
+
+	filename, err := cacheFile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected the second run to hit the cache entry of the first, got %v %v", entries, err)
+	}
+
+	if !strings.Contains(manifests[0], "NewStack") || manifests[1] != manifests[0] {
+		t.Errorf("the cached package writes the manifest\n%s\ninstead of\n%s", manifests[1], manifests[0])
+	}
+
+	if !strings.Contains(diagnostics[0], `"name":"Identity"`) || diagnostics[1] != diagnostics[0] {
+		t.Errorf("the cached package reports the skipped symbols\n%s\ninstead of\n%s", diagnostics[1], diagnostics[0])
+	}
+}
@@ -0,0 +1,241 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// checkContext is the number of unchanged lines around the changes of a diff hunk
+const checkContext = 3
+
+// checkMaxCells limits the lines compared line by line, larger changes are reported as a whole
+const checkMaxCells = 4 << 20
+
+var (
+	checkMu    sync.Mutex
+	checkDiffs []string
+)
+
+// checkFile records the diff of the file on disk to ba for the check subcommand
+func checkFile(filename string, ba []byte) error {
+	current, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil && bytes.Equal(current, ba) {
+		return nil
+	}
+
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	checkDiffs = append(checkDiffs, unifiedDiff(filename, string(current), string(ba)))
+
+	return nil
+}
+
+// checkRemoved records the stale file which would be removed for the check subcommand
+func checkRemoved(filename string) {
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		return
+	}
+
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	checkDiffs = append(checkDiffs, unifiedDiff(filename, string(current), ""))
+}
+
+// reportCheck prints the diffs recorded by the check subcommand and fails if there are any
+func reportCheck() error {
+	for _, diff := range checkDiffs {
+		fmt.Print(diff)
+	}
+
+	if len(checkDiffs) > 0 {
+		return fmt.Errorf("%d generated files are stale, regenerate them", len(checkDiffs))
+	}
+
+	return nil
+}
+
+// splitLines splits s into lines keeping their line breaks
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffOp is a line of a diff kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the edit script from a to b by the longest common subsequence of the lines
+// between their common prefix and suffix
+func diffLines(a []string, b []string) []diffOp {
+	ops := []diffOp{}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if len(ma)*len(mb) > checkMaxCells {
+		for _, line := range ma {
+			ops = append(ops, diffOp{'-', line})
+		}
+
+		for _, line := range mb {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of ma[i:] and mb[j:]
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(ma) || j < len(mb) {
+			switch {
+			case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+				ops = append(ops, diffOp{' ', ma[i]})
+				i++
+				j++
+			case i < len(ma) && (j == len(mb) || lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, diffOp{'-', ma[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', mb[j]})
+				j++
+			}
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	return ops
+}
+
+// unifiedDiff returns the unified diff of the file filename from a on disk to b generated
+func unifiedDiff(filename string, a string, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s (generated)\n", filename, filename)
+
+	for start := 0; start < len(ops); {
+		// find the next change and the end of the hunk around it
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+
+		if first == len(ops) {
+			break
+		}
+
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+
+			if next == len(ops) || next-end > 2*checkContext {
+				break
+			}
+
+			end = next
+		}
+
+		from := max(first-checkContext, start)
+		to := min(end+checkContext, len(ops))
+
+		// line numbers of the hunk start in a and b
+		la, lb := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				la++
+			}
+
+			if op.kind != '-' {
+				lb++
+			}
+		}
+
+		na, nb := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				na++
+			}
+
+			if op.kind != '-' {
+				nb++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(la, na), hunkRange(lb, nb))
+
+		for _, op := range ops[from:to] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = to
+	}
+
+	return sb.String()
+}
+
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		line--
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mpetavy/common"
+)
+
+// jsScope maps the JS names registered on an object to the symbols registered under them
+type jsScope map[string]string
+
+// claim registers the symbol under jsName and its suffixed variants, resolving a collision with
+// an already registered symbol by the -collisions rule
+func (scope jsScope) claim(jsName *string, symbol string, suffixes ...string) error {
+	base := *jsName
+
+	for i := 2; ; i++ {
+		other := ""
+		for _, suffix := range append([]string{""}, suffixes...) {
+			if s, ok := scope[*jsName+suffix]; ok {
+				other = s
+
+				break
+			}
+		}
+
+		if other == "" {
+			break
+		}
+
+		if *collisions != "suffix" {
+			return fmt.Errorf("JS name %s of %s collides with %s, rename one of them in the config file or resolve it by -collisions suffix", *jsName, symbol, other)
+		}
+
+		*jsName = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	for _, suffix := range append([]string{""}, suffixes...) {
+		scope[*jsName+suffix] = symbol
+	}
+
+	return nil
+}
+
+// checkCollisions detects bridged symbols registered under the same JS name, which would
+// silently shadow each other, like the functions MD5 and Md5 both named md5
+func (data *Data) checkCollisions() error {
+	scope := jsScope{}
+
+	for _, group := range data.Values {
+		for _, value := range group {
+			scope[value.Name] = common.Eval(value.Const, "constant ", "variable ") + value.Name
+		}
+	}
+
+	for _, typ := range data.Types {
+		scope[typ.Name] = "type " + typ.Name
+	}
+
+	if data.Profile != "" {
+		for _, name := range profiles[data.pkgPath].jsNames {
+			scope[name] = "the " + name + " of the profile " + data.Profile
+		}
+	}
+
+	// claimed by Go names, so the resolution does not depend on the scan order
+	indices := make([]int, len(data.Funcs))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.Slice(indices, func(i, j int) bool {
+		return data.Funcs[indices[i]].Name < data.Funcs[indices[j]].Name
+	})
+
+	for _, i := range indices {
+		f := &data.Funcs[i]
+
+		suffixes := []string{}
+		if f.Async != nil {
+			suffixes = append(suffixes, "Async")
+		}
+
+		err := scope.claim(&f.JsName, "function "+f.Name, suffixes...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range data.Types {
+		typ := &data.Types[i]
+
+		scope := jsScope{"__value": "the wrapped value"}
+		if typ.Stringer {
+			scope["toString"] = "the toString of " + typ.Name
+		}
+
+		for j := range typ.Fields {
+			err := scope.claim(&typ.Fields[j].JsName, "field "+typ.Name+"."+typ.Fields[j].Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		for j := range typ.Methods {
+			err := scope.claim(&typ.Methods[j].JsName, "method "+typ.Name+"."+typ.Methods[j].Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		for j := range typ.Properties {
+			err := scope.claim(&typ.Properties[j].JsName, "property "+typ.Name+"."+typ.Properties[j].Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mpetavy/common"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a batch of packages to generate, read from a YAML or JSON file
+type Config struct {
+	// Flags apply to all packages, given by name without leading dash
+	Flags    map[string]string `yaml:"flags"`
+	Packages []ConfigPackage   `yaml:"packages"`
+}
+
+type ConfigPackage struct {
+	Name     string `yaml:"name"`
+	Output   string `yaml:"output"`
+	Template string `yaml:"template"`
+	// Rename maps functions and methods (T.M) to the JS names they are registered as
+	Rename map[string]string `yaml:"rename"`
+	// Include and Exclude filter the bridged symbols by regular expressions like -include and -exclude
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// Properties maps types to whether their getter and setter pairs are bridged as properties,
+	// overriding -properties
+	Properties map[string]bool   `yaml:"properties"`
+	Flags      map[string]string `yaml:"flags"`
+}
+
+var (
+	renames          map[string]string
+	configIncludes   []string
+	configExcludes   []string
+	configProperties map[string]bool
+)
+
+func readConfig(filename string) (*Config, error) {
+	ba, err := os.ReadFile(filename)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	config := &Config{}
+
+	err = yaml.Unmarshal(ba, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", filename, err)
+	}
+
+	for i, pkg := range config.Packages {
+		if pkg.Name == "" {
+			return nil, fmt.Errorf("invalid config file %s: package %d without name", filename, i+1)
+		}
+
+		for _, pattern := range slices.Concat(pkg.Include, pkg.Exclude) {
+			_, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config file %s: package %s: %w", filename, pkg.Name, err)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// setFlags sets the flags given by name and returns a function restoring their previous values
+func setFlags(flags map[string]string) (func(), error) {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	prev := make(map[string]string)
+	restore := func() {
+		for name, value := range prev {
+			_ = flag.Set(name, value)
+		}
+	}
+
+	for _, name := range names {
+		fl := flag.Lookup(name)
+		if fl == nil {
+			restore()
+
+			return nil, fmt.Errorf("unknown flag in config file: %s", name)
+		}
+
+		prev[name] = fl.Value.String()
+
+		err := flag.Set(name, flags[name])
+		if err != nil {
+			restore()
+
+			return nil, fmt.Errorf("invalid value of flag %s in config file: %w", name, err)
+		}
+	}
+
+	return restore, nil
+}
+
+// runConfig generates the packages of the config file one after another, each with the
+// common and its own flags set
+func runConfig() error {
+	config, err := readConfig(*configFile)
+	if common.Error(err) {
+		return err
+	}
+
+	restoreCommon, err := setFlags(config.Flags)
+	if common.Error(err) {
+		return err
+	}
+
+	defer restoreCommon()
+
+	for _, pkg := range config.Packages {
+		flags := make(map[string]string)
+		for name, value := range pkg.Flags {
+			flags[name] = value
+		}
+
+		flags["n"] = pkg.Name
+		if pkg.Output != "" {
+			flags["o"] = pkg.Output
+		}
+
+		if pkg.Template != "" {
+			flags["t"] = pkg.Template
+		}
+
+		restore, err := setFlags(flags)
+		if common.Error(err) {
+			return err
+		}
+
+		renames, configIncludes, configExcludes, configProperties = pkg.Rename, pkg.Include, pkg.Exclude, pkg.Properties
+
+		err = runPackages()
+
+		renames, configIncludes, configExcludes, configProperties = nil, nil, nil, nil
+		restore()
+
+		if common.Error(err) {
+			return fmt.Errorf("%s: %w", pkg.Name, err)
+		}
+	}
+
+	return nil
+}
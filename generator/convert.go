@@ -0,0 +1,1048 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Param struct {
+	Name string
+	Type string
+	Expr ast.Expr
+	// Variadic marks a final ...T parameter, whose Type is the element type T
+	Variadic bool
+	// Key is the property of a result in the object returned by -multi-return object
+	Key string
+}
+
+// elem returns the element of the variadic parameter p
+func (p Param) elem() Param {
+	return Param{Name: p.Name, Type: p.Type, Expr: p.Expr.(*ast.Ellipsis).Elt}
+}
+
+var helperImports = map[string][]string{
+	"async":    {"fmt", "sync"},
+	"export":   {"reflect"},
+	"chan":     {"reflect"},
+	"context":  {"context", "sync"},
+	"date":     {"time"},
+	"duration": {"fmt", "regexp", "strconv", "time"},
+	"enum":     {"fmt"},
+	"freeze":   {"reflect"},
+	"hex":      {"encoding/hex", "fmt"},
+	"int64":    {"fmt", "math", "math/big", "strconv"},
+	"map":      {"fmt", "reflect", "sort"},
+	"null":     {"reflect"},
+	"options":  {"fmt", "reflect", "strings"},
+	"recover":  {"fmt", "runtime/debug"},
+	"rune":     {"fmt"},
+	"stream":   {"bytes", "io", "strings"},
+}
+
+// generatedPackageNames returns the names of the packages the generated code may import besides
+// those of the bridged signatures
+func generatedPackageNames() []string {
+	paths := []string{*gojaImport, "bytes", "encoding/hex", "errors", "fmt", "math/big", "slices", "strconv", "time", "unicode/utf8", requireImport, runtimeImport}
+	for _, imports := range helperImports {
+		paths = append(paths, imports...)
+	}
+
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, path[strings.LastIndex(path, "/")+1:])
+	}
+
+	return names
+}
+
+// runtimeImport is the import path of the package providing the helpers with -shared-runtime,
+// versioned alongside the generator by runtimeVersion
+const runtimeImport = "github.com/mpetavy/goja_go/runtime"
+
+// runtimeVersion is the version of the runtime package the generated code requires, increased
+// whenever the generator needs helpers the older package does not provide
+const runtimeVersion = 1
+
+// runtimeGojaImport is the goja package the runtime package is built with
+const runtimeGojaImport = "github.com/dop251/goja"
+
+// localHelpers declare functions named after the bridge and are generated even with -shared-runtime
+var localHelpers = []string{"async", "context"}
+
+func (data *Data) useHelper(name string) {
+	data.Helpers[name] = true
+
+	if *sharedRuntime && !slices.Contains(localHelpers, name) {
+		data.Runtime = runtimeVersion
+		data.addImportPath(runtimeImport)
+
+		return
+	}
+
+	for _, path := range helperImports[name] {
+		data.addImportPath(path)
+	}
+}
+
+// reservedNames are identifiers referenced by generated wrapper bodies which
+// a parameter of the same name would shadow
+var reservedNames = []string{"bytes", "err", "errors", "fmt", "goja", "hex", "reflect", "slices", "sort", "time", "utf8"}
+
+func (data *Data) formatParams(fields *ast.FieldList) []Param {
+	params := []Param{}
+	reserved := append([]string{data.InputPkg}, reservedNames...)
+
+	for _, field := range fields.List {
+		typ := data.formatType(field.Type)
+
+		ellipsis, variadic := field.Type.(*ast.Ellipsis)
+		if variadic {
+			typ = data.formatType(ellipsis.Elt)
+		}
+
+		ast.Inspect(field.Type, func(node ast.Node) bool {
+			if sel, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					reserved = append(reserved, ident.Name)
+				}
+			}
+
+			return true
+		})
+
+		names := []string{""}
+		if len(field.Names) > 0 {
+			names = names[:0]
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+		}
+
+		for _, name := range names {
+			params = append(params, Param{
+				Name:     name,
+				Type:     typ,
+				Expr:     field.Type,
+				Variadic: variadic,
+			})
+		}
+	}
+
+	for i, p := range params {
+		if p.Name == "" || p.Name == "_" || strings.HasPrefix(p.Name, "bridge") || slices.Contains(reserved, p.Name) {
+			params[i].Name = fmt.Sprintf("bridgeParam%d", i)
+		}
+	}
+
+	return params
+}
+
+func (data *Data) formatResults(fields *ast.FieldList) []Param {
+	results := []Param{}
+
+	if fields == nil {
+		return results
+	}
+
+	for _, field := range fields.List {
+		typ := data.formatType(field.Type)
+
+		ellipsis, variadic := field.Type.(*ast.Ellipsis)
+		if variadic {
+			typ = data.formatType(ellipsis.Elt)
+		}
+
+		for i := range max(1, len(field.Names)) {
+			key := fmt.Sprintf("result%d", len(results))
+			if i < len(field.Names) && field.Names[i].Name != "_" {
+				key = field.Names[i].Name
+			}
+
+			results = append(results, Param{
+				Name: fmt.Sprintf("bridgeRes%d", len(results)),
+				Type: typ,
+				Expr: field.Type,
+				Key:  key,
+			})
+		}
+	}
+
+	return results
+}
+
+func (data *Data) isInterface(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.InterfaceType:
+		return true
+	case *ast.Ident:
+		if t.Name == "any" {
+			return true
+		}
+
+		spec, ok := data.types[t.Name]
+		if !ok {
+			return false
+		}
+
+		_, ok = spec.Type.(*ast.InterfaceType)
+
+		return ok
+	}
+
+	return false
+}
+
+func isBytes(expr ast.Expr) bool {
+	array, ok := expr.(*ast.ArrayType)
+	if !ok {
+		return false
+	}
+
+	elt, ok := array.Elt.(*ast.Ident)
+
+	return ok && (elt.Name == "byte" || elt.Name == "uint8")
+}
+
+func (data *Data) isNilable(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return true
+	case *ast.ArrayType:
+		return t.Len == nil
+	case *ast.Ident:
+		if t.Name == "any" {
+			return true
+		}
+
+		spec, ok := data.types[t.Name]
+		if !ok || spec.TypeParams != nil {
+			return false
+		}
+
+		return data.isNilable(spec.Type)
+	}
+
+	return false
+}
+
+func (data *Data) underlyingComposite(expr ast.Expr) ast.Expr {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	spec, ok := data.types[ident.Name]
+	if !ok || spec.Assign.IsValid() || spec.TypeParams != nil {
+		return nil
+	}
+
+	switch t := spec.Type.(type) {
+	case *ast.MapType:
+		return t
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return t
+		}
+	case *ast.Ident:
+		return data.underlyingComposite(t)
+	}
+
+	return nil
+}
+
+func (data *Data) isGojaType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	if pkg := data.importedPackage(x); pkg != nil {
+		return pkg.Path() == *gojaImport
+	}
+
+	return data.resolveImport(x.Name) == *gojaImport
+}
+
+func (data *Data) convertParam(f *Func, index int, p Param) (string, string) {
+	arg := fmt.Sprintf("bridgeArg%d", index)
+
+	if data.isGojaType(p.Expr) {
+		return p.Type, p.Name
+	}
+
+	if star, ok := p.Expr.(*ast.StarExpr); ok && data.isInterface(star.X) {
+		data.useHelper("assign")
+
+		data.useHelper("export")
+
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s %s", arg, p.Type[1:]),
+			fmt.Sprintf("bridgeExport(bridge.vm, %s, &%s)", p.Name, arg))
+		f.After = append(f.After, fmt.Sprintf("bridgeAssign(bridge.vm, %s, %s)", p.Name, arg))
+
+		return "goja.Value", "&" + arg
+	}
+
+	if *bytesAsHex && isBytes(p.Expr) {
+		data.useHelper("hex")
+
+		if p.Expr.(*ast.ArrayType).Len == nil {
+			f.Before = append(f.Before, fmt.Sprintf("%s := bridgeDecodeHex(bridge.vm, %s, -1)", arg, p.Name))
+		} else {
+			f.Before = append(f.Before,
+				fmt.Sprintf("var %s %s", arg, p.Type),
+				fmt.Sprintf("copy(%s[:], bridgeDecodeHex(bridge.vm, %s, len(%s)))", arg, p.Name, arg))
+		}
+
+		return "goja.Value", arg
+	}
+
+	if *arrayBuffer != "" && isBytes(p.Expr) && p.Expr.(*ast.ArrayType).Len == nil {
+		data.useHelper("bytes")
+
+		if *arrayBuffer == "copy" {
+			data.addImportPath("bytes")
+
+			f.Before = append(f.Before, fmt.Sprintf("%s := bytes.Clone(bridgeBytes(bridge.vm, %s))", arg, p.Name))
+		} else {
+			f.Before = append(f.Before, fmt.Sprintf("%s := bridgeBytes(bridge.vm, %s)", arg, p.Name))
+		}
+
+		return "goja.Value", arg
+	}
+
+	if *duration != "" && p.Type == "time.Duration" {
+		data.useHelper("duration")
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridgeDuration(bridge.vm, %s)", arg, p.Name))
+
+		return "goja.Value", arg
+	}
+
+	if *int64Mode != "number" && (p.Type == "int64" || p.Type == "uint64") {
+		data.useHelper("int64")
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridge%s(bridge.vm, %s)", arg, upper1st(p.Type), p.Name))
+
+		return "goja.Value", arg
+	}
+
+	if *timeAsDate && p.Type == "time.Time" {
+		data.useHelper("date")
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridgeTime(bridge.vm, %s)", arg, p.Name))
+
+		return "goja.Value", arg
+	}
+
+	if *options && data.isOptions(p.Expr) {
+		data.useHelper("options")
+
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s []%s", arg, p.Type),
+			fmt.Sprintf("bridgeOptions(bridge.vm, %s, &%s)", p.Name, arg))
+
+		return "goja.Value", arg + "..."
+	}
+
+	if *runes && (p.Type == "rune" || p.Type == "byte") {
+		data.useHelper("rune")
+
+		if p.Type == "byte" {
+			f.Before = append(f.Before, fmt.Sprintf("%s := byte(bridgeRune(bridge.vm, %s, 0xff))", arg, p.Name))
+		} else {
+			data.addImportPath("unicode/utf8")
+
+			f.Before = append(f.Before, fmt.Sprintf("%s := bridgeRune(bridge.vm, %s, utf8.MaxRune)", arg, p.Name))
+		}
+
+		return "goja.Value", arg
+	}
+
+	if ident, ok := p.Expr.(*ast.Ident); ok && data.isBridgedType(ident.Name) {
+		data.useHelper("export")
+
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s %s", arg, p.Type),
+			fmt.Sprintf("bridgeExport(bridge.vm, %s, &%s)", p.Name, arg))
+
+		return "goja.Value", arg
+	}
+
+	switch data.stream(p.Expr) {
+	case "Reader":
+		data.useHelper("stream")
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridgeReader(bridge.vm, %s)", arg, p.Name))
+
+		return "goja.Value", arg
+	case "Writer":
+		data.useHelper("stream")
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridgeWriter(bridge.vm, %s)", arg, p.Name))
+
+		return "goja.Value", arg
+	case "":
+	default:
+		data.useHelper("export")
+
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s %s", arg, p.Type),
+			fmt.Sprintf("bridgeExport(bridge.vm, %s, &%s)", p.Name, arg))
+
+		return "goja.Value", arg
+	}
+
+	if ident, ok := p.Expr.(*ast.Ident); ok {
+		if reason := data.unimplementable(ident.Name); reason != "" {
+			data.skipImplementation(ident.Name, reason)
+		}
+	}
+
+	if ident, ok := p.Expr.(*ast.Ident); ok && data.isImplementable(ident.Name) {
+		if data.implemented == nil {
+			data.implemented = make(map[string]bool)
+		}
+
+		data.implemented[ident.Name] = true
+
+		f.Before = append(f.Before, fmt.Sprintf("%s := bridge.impl%s(%s)", arg, ident.Name, p.Name))
+
+		return "goja.Value", arg
+	}
+
+	if fn := data.funcType(p.Expr); fn != nil {
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s %s", arg, p.Type),
+			data.formatCallback(p.Name, arg, fn))
+
+		return "goja.Value", arg
+	}
+
+	if data.isNilable(p.Expr) {
+		data.useHelper("export")
+
+		typ := p.Type
+		conv := arg
+
+		if underlying := data.underlyingComposite(p.Expr); underlying != nil {
+			typ = data.formatType(underlying)
+			conv = fmt.Sprintf("%s(%s)", p.Type, arg)
+		}
+
+		f.Before = append(f.Before,
+			fmt.Sprintf("var %s %s", arg, typ),
+			fmt.Sprintf("bridgeExport(bridge.vm, %s, &%s)", p.Name, arg))
+
+		return "goja.Value", conv
+	}
+
+	return p.Type, p.Name
+}
+
+// convertVariadic converts the arguments of the variadic parameter p one by one like
+// convertParam does, collecting them in a slice passed on spread
+func (data *Data) convertVariadic(f *Func, index int, p Param) (string, string) {
+	elem := p.elem()
+	elem.Name = "bridgeElem"
+
+	conv := Func{}
+
+	typ, arg := data.convertParam(&conv, index, elem)
+	if len(conv.Before) == 0 && arg == elem.Name {
+		return "..." + typ, p.Name + "..."
+	}
+
+	args := fmt.Sprintf("bridgeArgs%d", index)
+
+	f.Before = append(f.Before,
+		fmt.Sprintf("%s := make([]%s, len(%s))", args, p.Type, p.Name),
+		fmt.Sprintf("for bridgeI, bridgeElem := range %s {\n%s\n%s[bridgeI] = %s\n}", p.Name, strings.Join(conv.Before, "\n"), args, arg))
+
+	return "..." + typ, args + "..."
+}
+
+// isOptions reports whether expr is a variadic functional option like ...Option,
+// with Option declared as func(*config) and config a struct of the package
+func (data *Data) isOptions(expr ast.Expr) bool {
+	ellipsis, ok := expr.(*ast.Ellipsis)
+	if !ok {
+		return false
+	}
+
+	ident, ok := ellipsis.Elt.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	spec, ok := data.types[ident.Name]
+	if !ok || spec.TypeParams != nil {
+		return false
+	}
+
+	fn, ok := spec.Type.(*ast.FuncType)
+	if !ok || fn.Results != nil || len(fn.Params.List) != 1 || len(fn.Params.List[0].Names) > 1 {
+		return false
+	}
+
+	return data.isStruct(fn.Params.List[0].Type)
+}
+
+// funcType returns the non variadic function type expr is or is declared as, if any and
+// its signature can be written outside the package
+func (data *Data) funcType(expr ast.Expr) *ast.FuncType {
+	if ident, ok := expr.(*ast.Ident); ok {
+		spec, ok := data.types[ident.Name]
+		if !ok || spec.TypeParams != nil {
+			return nil
+		}
+
+		expr = spec.Type
+	}
+
+	fn, ok := expr.(*ast.FuncType)
+	if !ok {
+		return nil
+	}
+
+	fields := fn.Params.List
+	if fn.Results != nil {
+		fields = append(slices.Clone(fields), fn.Results.List...)
+	}
+
+	for i, field := range fields {
+		if _, ok := field.Type.(*ast.Ellipsis); ok && i < len(fn.Params.List) {
+			return nil
+		}
+
+		unexported := false
+		ast.Inspect(field.Type, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && !ident.IsExported() {
+				_, unexported = data.types[ident.Name]
+			}
+
+			return !unexported
+		})
+
+		if unexported {
+			return nil
+		}
+	}
+
+	return fn
+}
+
+// isImplementable reports whether name is an exported interface of the package, which
+// JS objects can implement as all its methods can be called as callbacks
+func (data *Data) isImplementable(name string) bool {
+	spec, ok := data.types[name]
+	if !ok || !spec.Name.IsExported() || spec.TypeParams != nil {
+		return false
+	}
+
+	iface, ok := spec.Type.(*ast.InterfaceType)
+	if !ok || len(iface.Methods.List) == 0 {
+		return false
+	}
+
+	for _, method := range iface.Methods.List {
+		if len(method.Names) != 1 || !method.Names[0].IsExported() || data.funcType(method.Type) == nil {
+			return false
+		}
+	}
+
+	return data.unimplementable(name) == ""
+}
+
+// unimplementable returns why JS objects cannot implement the interface name of the package by
+// the signature of one of its methods, empty if they can or name is no such interface
+func (data *Data) unimplementable(name string) string {
+	spec, ok := data.types[name]
+	if !ok {
+		return ""
+	}
+
+	iface, ok := spec.Type.(*ast.InterfaceType)
+	if !ok || iface.Methods == nil {
+		return ""
+	}
+
+	for _, method := range iface.Methods.List {
+		if len(method.Names) != 1 {
+			continue
+		}
+
+		if reason := data.unbridgeableType(method.Type); reason != "" {
+			return fmt.Sprintf("method %s has %s", method.Names[0].Name, reason)
+		}
+	}
+
+	return ""
+}
+
+// skipImplementation records once that JS objects passed as the interface name are exported to
+// Go values instead of implementing it
+func (data *Data) skipImplementation(name string, reason string) {
+	if slices.ContainsFunc(data.Skipped, func(skip Skip) bool {
+		return skip.Kind == "interface" && skip.Name == name
+	}) {
+		return
+	}
+
+	data.skip("interface", name, "not implementable by JS objects: "+reason, data.types[name])
+}
+
+// scanInterfaces collects the interfaces JS objects are passed as by the bridged functions,
+// whose adapters delegate the methods to the JS object
+func (data *Data) scanInterfaces() {
+	names := []string{}
+	for name := range data.implemented {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		data.useHelper("export")
+	}
+
+	for _, name := range names {
+		spec := data.types[name]
+
+		iface := Type{
+			Name: name,
+			Type: data.formatType(spec.Name),
+		}
+
+		for _, method := range spec.Type.(*ast.InterfaceType).Methods.List {
+			jsName := jsName(method.Names[0].Name)
+
+			params, signature, body := data.formatCallbackBody("bridgeImpl.obj", method.Type.(*ast.FuncType))
+
+			iface.Methods = append(iface.Methods, Func{
+				Name:    method.Names[0].Name,
+				JsName:  jsName,
+				Params:  params,
+				Results: signature,
+				Before: append([]string{
+					fmt.Sprintf("bridgeFn, ok := goja.AssertFunction(bridgeImpl.obj.Get(%q))", jsName),
+					fmt.Sprintf("if !ok {\npanic(bridge.vm.NewTypeError(\"%s.%s is not a function\"))\n}", name, jsName),
+				}, body...),
+			})
+		}
+
+		data.Interfaces = append(data.Interfaces, iface)
+	}
+}
+
+// formatCallback formats the statement assigning arg a Go function calling the JS function
+// value name, which converts the arguments to and the results from JS. A JS exception is
+// returned as trailing error result if the function has one and thrown on otherwise.
+func (data *Data) formatCallback(name string, arg string, fn *ast.FuncType) string {
+	params, signature, body := data.formatCallbackBody("goja.Undefined()", fn)
+
+	lines := []string{fmt.Sprintf("%s = func%s %s {", arg, params, signature)}
+	lines = append(lines, body...)
+	lines = append(lines, "}")
+
+	return fmt.Sprintf("if bridgeFn, ok := goja.AssertFunction(%s); ok {\n%s\n} else if %s != nil && !goja.IsUndefined(%s) && !goja.IsNull(%s) {\npanic(bridge.vm.NewTypeError(\"%s is not a function\"))\n}",
+		name, strings.Join(lines, "\n"), name, name, name, name)
+}
+
+// formatCallbackBody formats the parameters, results and body of a Go function of type fn
+// calling the JS function bridgeFn on this
+func (data *Data) formatCallbackBody(this string, fn *ast.FuncType) (string, string, []string) {
+	params := []string{}
+	values := []string{}
+
+	for i, p := range data.formatParams(fn.Params) {
+		params = append(params, fmt.Sprintf("bridgeCbArg%d %s", i, p.Type))
+		values = append(values, fmt.Sprintf("bridge.vm.ToValue(bridgeCbArg%d)", i))
+	}
+
+	results := data.formatResults(fn.Results)
+
+	hasErr := len(results) > 0 && results[len(results)-1].Type == "error"
+	values = append([]string{this}, values...)
+
+	types := []string{}
+	zeros := []string{}
+	names := []string{}
+	for i, r := range results {
+		types = append(types, r.Type)
+		names = append(names, fmt.Sprintf("bridgeCbRes%d", i))
+		zeros = append(zeros, fmt.Sprintf("var bridgeCbRes%d %s", i, r.Type))
+	}
+
+	signature := strings.Join(types, ", ")
+	if len(types) > 1 {
+		signature = "(" + signature + ")"
+	}
+
+	lines := zeros
+
+	onErr := "panic(err)"
+	if hasErr {
+		onErr = fmt.Sprintf("bridgeCbRes%d = err\nreturn %s", len(results)-1, strings.Join(names, ", "))
+	}
+
+	call := "_, err := "
+	exported := results
+	if hasErr {
+		exported = results[:len(results)-1]
+	}
+
+	if len(exported) > 0 {
+		call = "bridgeCbValue, err := "
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("%sbridgeFn(%s)", call, strings.Join(values, ", ")),
+		fmt.Sprintf("if err != nil {\n%s\n}", onErr))
+
+	for i := range exported {
+		value := "bridgeCbValue"
+		if len(exported) > 1 {
+			value = fmt.Sprintf("bridgeCbValue.ToObject(bridge.vm).Get(\"%d\")", i)
+		}
+
+		lines = append(lines, fmt.Sprintf("if err := bridge.vm.ExportTo(%s, &bridgeCbRes%d); err != nil {\npanic(bridge.vm.NewGoError(err))\n}", value, i))
+	}
+
+	if len(results) > 0 {
+		lines = append(lines, "return "+strings.Join(names, ", "))
+	}
+
+	return "(" + strings.Join(params, ", ") + ")", signature, lines
+}
+
+func (data *Data) isContext(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" {
+		return false
+	}
+
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	if pkg := data.importedPackage(x); pkg != nil {
+		return pkg.Path() == "context"
+	}
+
+	return data.resolveImport(x.Name) == "context"
+}
+
+// streams are the io interfaces mapped to JS stream-like objects
+var streams = []string{"Reader", "Writer", "Closer", "ReadCloser", "WriteCloser", "ReadWriter", "ReadWriteCloser"}
+
+// stream returns the name of the io interface expr refers to, if any
+func (data *Data) stream(expr ast.Expr) string {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || !slices.Contains(streams, sel.Sel.Name) {
+		return ""
+	}
+
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+
+	path := data.resolveImport(x.Name)
+	if pkg := data.importedPackage(x); pkg != nil {
+		path = pkg.Path()
+	}
+
+	if path != "io" {
+		return ""
+	}
+
+	return sel.Sel.Name
+}
+
+func (data *Data) isSlice(expr ast.Expr) bool {
+	if t, ok := expr.(*ast.ArrayType); ok {
+		return t.Len == nil
+	}
+
+	t, ok := data.underlyingComposite(expr).(*ast.ArrayType)
+
+	return ok && t.Len == nil
+}
+
+func (data *Data) isChan(expr ast.Expr) bool {
+	if _, ok := expr.(*ast.ChanType); ok {
+		return true
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	spec, ok := data.types[ident.Name]
+	if !ok || spec.TypeParams != nil {
+		return false
+	}
+
+	return data.isChan(spec.Type)
+}
+
+func (data *Data) isMap(expr ast.Expr) bool {
+	if _, ok := expr.(*ast.MapType); ok {
+		return true
+	}
+
+	_, ok := data.underlyingComposite(expr).(*ast.MapType)
+
+	return ok
+}
+
+func (data *Data) bridgedType(expr ast.Expr) (string, bool, bool) {
+	star, pointer := expr.(*ast.StarExpr)
+	if pointer {
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok || !data.isBridgedType(ident.Name) {
+		return "", false, false
+	}
+
+	return ident.Name, pointer, true
+}
+
+func (data *Data) isStruct(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	spec, ok := data.types[ident.Name]
+	if !ok || spec.TypeParams != nil {
+		return false
+	}
+
+	_, ok = spec.Type.(*ast.StructType)
+
+	return ok
+}
+
+func (data *Data) convertResult(r Param) (string, string) {
+	if data.isGojaType(r.Expr) {
+		return r.Type, r.Name
+	}
+
+	if *copySlices && data.isSlice(r.Expr) {
+		data.addImportPath("slices")
+
+		r.Name = fmt.Sprintf("slices.Clone(%s)", r.Name)
+	}
+
+	if *duration != "" && r.Type == "time.Duration" {
+		switch *duration {
+		case "string":
+			return "string", fmt.Sprintf("%s.String()", r.Name)
+		case "iso":
+			data.useHelper("duration")
+
+			return "string", fmt.Sprintf("bridgeISODuration(%s)", r.Name)
+		}
+
+		return "float64", fmt.Sprintf("float64(%s) / float64(time.Millisecond)", r.Name)
+	}
+
+	if *int64Mode != "number" && (r.Type == "int64" || r.Type == "uint64") {
+		if *int64Mode == "string" {
+			data.addImportPath("strconv")
+
+			if r.Type == "int64" {
+				return "string", fmt.Sprintf("strconv.FormatInt(%s, 10)", r.Name)
+			}
+
+			return "string", fmt.Sprintf("strconv.FormatUint(%s, 10)", r.Name)
+		}
+
+		data.addImportPath("math/big")
+
+		if r.Type == "int64" {
+			return "goja.Value", fmt.Sprintf("bridge.vm.ToValue(new(big.Int).SetInt64(%s))", r.Name)
+		}
+
+		return "goja.Value", fmt.Sprintf("bridge.vm.ToValue(new(big.Int).SetUint64(%s))", r.Name)
+	}
+
+	if *timeAsDate && r.Type == "time.Time" {
+		data.useHelper("date")
+
+		return "goja.Value", fmt.Sprintf("bridgeDate(bridge.vm, %s)", r.Name)
+	}
+
+	if *runes && r.Type == "rune" {
+		return "string", fmt.Sprintf("string(%s)", r.Name)
+	}
+
+	if *mapReturn != "" && data.isMap(r.Expr) {
+		data.useHelper("map")
+
+		if *mapReturn == "map" {
+			return "goja.Value", fmt.Sprintf("bridgeToMap(bridge.vm, %s)", r.Name)
+		}
+
+		return "goja.Value", fmt.Sprintf("bridgeToObject(bridge.vm, %s)", r.Name)
+	}
+
+	if typeName, pointer, ok := data.bridgedType(r.Expr); ok {
+		if pointer {
+			return "goja.Value", fmt.Sprintf("bridge.wrap%s(%s)", typeName, r.Name)
+		}
+
+		return "goja.Value", fmt.Sprintf("bridge.wrap%s(&%s)", typeName, r.Name)
+	}
+
+	if data.isChan(r.Expr) {
+		data.useHelper("chan")
+
+		return "goja.Value", fmt.Sprintf("bridgeChan(bridge.vm, %s)", r.Name)
+	}
+
+	if data.stream(r.Expr) != "" {
+		data.useHelper("stream")
+
+		return "goja.Value", fmt.Sprintf("bridgeStream(bridge.vm, %s)", r.Name)
+	}
+
+	if *freeze && data.isStruct(r.Expr) {
+		data.useHelper("freeze")
+
+		return "goja.Value", fmt.Sprintf("bridgeFreeze(bridge.vm, %s)", r.Name)
+	}
+
+	if *arrayBuffer != "" && isBytes(r.Expr) && r.Expr.(*ast.ArrayType).Len == nil {
+		data.useHelper("bytes")
+
+		if *arrayBuffer == "copy" {
+			data.addImportPath("bytes")
+
+			return "goja.Value", fmt.Sprintf("bridgeArrayBuffer(bridge.vm, bytes.Clone(%s))", r.Name)
+		}
+
+		return "goja.Value", fmt.Sprintf("bridgeArrayBuffer(bridge.vm, %s)", r.Name)
+	}
+
+	if *bytesAsHex && isBytes(r.Expr) {
+		if r.Expr.(*ast.ArrayType).Len == nil {
+			data.useHelper("hex")
+
+			return "goja.Value", fmt.Sprintf("bridgeEncodeHex(bridge.vm, %s)", r.Name)
+		}
+
+		data.addImportPath("encoding/hex")
+
+		return "string", fmt.Sprintf("hex.EncodeToString(%s[:])", r.Name)
+	}
+
+	if data.isNilable(r.Expr) && !data.isInterface(r.Expr) {
+		data.useHelper("null")
+
+		return "goja.Value", fmt.Sprintf("bridgeNull(bridge.vm, %s)", r.Name)
+	}
+
+	return r.Type, r.Name
+}
+
+func (data *Data) convertResults(f *Func, results []Param) {
+	names := []string{}
+	types := []string{}
+	exprs := []string{}
+	converted := false
+
+	for _, result := range results {
+		typ, expr := data.convertResult(result)
+
+		names = append(names, result.Name)
+		types = append(types, typ)
+		exprs = append(exprs, expr)
+		converted = converted || expr != result.Name
+	}
+
+	if len(results) > 0 && results[len(results)-1].Type == "error" {
+		last := len(results) - 1
+		types, exprs = types[:last], exprs[:last]
+
+		if *multiReturn == "object" && len(exprs) > 1 {
+			types, exprs = []string{"goja.Value"}, []string{resultObject(results[:last], exprs)}
+		}
+
+		f.Returns = strings.Join(names, ", ") + " :="
+
+		if *errorMode == "tuple" {
+			f.Results = "goja.Value"
+			f.After = append(f.After,
+				"var bridgeErr goja.Value = goja.Null()",
+				fmt.Sprintf("if %s != nil {\nbridgeErr = bridge.vm.NewGoError(%s)\n}", names[last], names[last]),
+				fmt.Sprintf("return bridge.vm.NewArray(%s)", strings.Join(append(exprs, "bridgeErr"), ", ")))
+
+			return
+		}
+
+		f.Results = strings.Join(types, ", ")
+		if len(types) > 1 {
+			f.Results = "(" + f.Results + ")"
+		}
+
+		f.After = append(f.After, fmt.Sprintf("if %s != nil {\npanic(bridge.vm.NewGoError(%s))\n}", names[last], names[last]))
+		if len(exprs) > 0 {
+			f.After = append(f.After, "return "+strings.Join(exprs, ", "))
+		}
+
+		return
+	}
+
+	switch {
+	case *commaOk && len(results) == 2 && results[1].Type == "bool":
+		f.Results = "goja.Value"
+		f.Returns = strings.Join(names, ", ") + " :="
+		f.After = append(f.After,
+			fmt.Sprintf("if !%s {\nreturn goja.Undefined()\n}", names[1]),
+			fmt.Sprintf("return bridge.vm.ToValue(%s)", exprs[0]))
+	case *multiReturn == "object" && len(results) > 1:
+		f.Results = "goja.Value"
+		f.Returns = strings.Join(names, ", ") + " :="
+		f.After = append(f.After, "return "+resultObject(results, exprs))
+	case converted || (len(f.After) > 0 && len(results) > 0):
+		f.Results = strings.Join(types, ", ")
+		if len(types) > 1 {
+			f.Results = "(" + f.Results + ")"
+		}
+		f.Returns = strings.Join(names, ", ") + " :="
+		f.After = append(f.After, "return "+strings.Join(exprs, ", "))
+	}
+}
+
+// resultObject returns the JS object of the converted results exprs keyed by the results
+func resultObject(results []Param, exprs []string) string {
+	entries := []string{}
+	for i, expr := range exprs {
+		entries = append(entries, fmt.Sprintf("%q: %s", results[i].Key, expr))
+	}
+
+	return fmt.Sprintf("bridge.vm.ToValue(map[string]interface{}{%s})", strings.Join(entries, ", "))
+}
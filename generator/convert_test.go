@@ -0,0 +1,427 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPointerToInterface(t *testing.T) {
+	runBridge(t, "unmarshal", bridgeTest{
+		Script: `
+			const data = Array.from('{"name":"goja","tags":["a","b"]}', c => c.charCodeAt(0));
+			const v = {kept: true};
+
+			bridge.unmarshal(data, v);
+
+			if (v.name !== "goja" || v.tags.length !== 2 || v.tags[1] !== "b" || v.kept !== true) {
+				throw new Error("unmarshal does not write back: " + JSON.stringify(v));
+			}
+
+			let threw = false;
+			try {
+				bridge.unmarshal(Array.from("{", c => c.charCodeAt(0)), {});
+			} catch (e) {
+				threw = true;
+			}
+
+			if (!threw) {
+				throw new Error("unmarshal does not throw its error");
+			}`,
+	})
+}
+
+func TestCommaOk(t *testing.T) {
+	runBridge(t, "commaok", bridgeTest{
+		Args: []string{"-comma-ok"},
+		Script: `
+			if (bridge.lookup("a") !== "A") {
+				throw new Error("lookup of a returns " + bridge.lookup("a"));
+			}
+
+			if (bridge.lookup("b") !== undefined) {
+				throw new Error("lookup of b returns " + bridge.lookup("b"));
+			}`,
+	})
+
+	runBridge(t, "commaok", bridgeTest{
+		Script: `
+			const result = bridge.lookup("b");
+
+			if (!Array.isArray(result) || result.length !== 2 || result[1] !== false) {
+				throw new Error("lookup of b without -comma-ok returns " + JSON.stringify(result));
+			}`,
+	})
+}
+
+func TestBytesAsHex(t *testing.T) {
+	runBridge(t, "signature", bridgeTest{
+		Args: []string{"-bytes-as-hex"},
+		Script: `
+			const sig = "ab" + "00".repeat(62) + "cd";
+
+			if (!bridge.verify(sig, "6d7367")) {
+				throw new Error("verify rejects the hex signature");
+			}
+
+			if (bridge.verify("AB" + "00".repeat(62) + "CD", "6d7368")) {
+				throw new Error("verify accepts the wrong message");
+			}
+
+			for (const wrong of ["ab", sig + "00", "zz".repeat(64)]) {
+				let threw = false;
+				try {
+					bridge.verify(wrong, "6d7367");
+				} catch (e) {
+					threw = true;
+				}
+
+				if (!threw) {
+					throw new Error("verify accepts the signature " + wrong);
+				}
+			}
+
+			if (bridge.sign("") !== "deadbeef") {
+				throw new Error("sign returns " + bridge.sign(""));
+			}`,
+	})
+}
+
+func TestNamedMap(t *testing.T) {
+	runBridge(t, "named", bridgeTest{
+		Script: `
+			const s = bridge.format({Accept: ["text/html", "text/plain"], Host: ["example.com"]});
+
+			if (s !== "Accept: text/html,text/plain\nHost: example.com") {
+				throw new Error("format returns " + s);
+			}
+
+			if (bridge.count(["a", "b", "c"]) !== 3) {
+				throw new Error("count returns " + bridge.count(["a", "b", "c"]));
+			}`,
+	})
+}
+
+func TestFreeze(t *testing.T) {
+	runBridge(t, "frozen", bridgeTest{
+		Args: []string{"-freeze"},
+		Script: `
+			"use strict";
+
+			const p = bridge.origin();
+
+			if (p.X !== 1 || p.Y !== 2 || !Object.isFrozen(p)) {
+				throw new Error("origin returns no frozen point: " + JSON.stringify(p));
+			}
+
+			let threw = false;
+			try {
+				p.X = 5;
+			} catch (e) {
+				threw = e instanceof TypeError;
+			}
+
+			if (!threw || p.X !== 1) {
+				throw new Error("the assignment of a frozen field does not throw");
+			}`,
+	})
+}
+
+func TestDuration(t *testing.T) {
+	runBridge(t, "durations", bridgeTest{
+		Args: []string{"-duration", "ms"},
+		Script: `
+			bridge.sleep(250);
+			if (bridge.slept() !== 250) {
+				throw new Error("sleep of 250 ms slept " + bridge.slept());
+			}
+
+			bridge.sleep("500ms");
+			if (bridge.slept() !== 500) {
+				throw new Error("sleep of 500ms slept " + bridge.slept());
+			}`,
+	})
+
+	runBridge(t, "durations", bridgeTest{
+		Args: []string{"-duration", "string"},
+		Script: `
+			bridge.sleep(1500);
+			if (bridge.slept() !== "1.5s") {
+				throw new Error("sleep of 1500 ms slept " + bridge.slept());
+			}
+
+			bridge.sleep("500ms");
+			if (bridge.slept() !== "500ms") {
+				throw new Error("sleep of 500ms slept " + bridge.slept());
+			}`,
+	})
+}
+
+func TestMapReturn(t *testing.T) {
+	runBridge(t, "maps", bridgeTest{
+		Args: []string{"-map-return", "object"},
+		Script: `
+			const counts = bridge.counts();
+
+			if (counts instanceof Map || JSON.stringify(counts) !== '{"a":1,"b":2,"c":3}') {
+				throw new Error("counts returns " + JSON.stringify(counts));
+			}
+
+			if (Object.keys(bridge.names()).join() !== "1,2,10" || bridge.names()["10"] !== "ten") {
+				throw new Error("names returns " + JSON.stringify(bridge.names()));
+			}`,
+	})
+
+	runBridge(t, "maps", bridgeTest{
+		Args: []string{"-map-return", "map"},
+		Script: `
+			// Go iterates maps in random order
+			for (let i = 0; i < 20; i++) {
+				const counts = bridge.counts();
+
+				if (!(counts instanceof Map) || JSON.stringify([...counts]) !== '[["a",1],["b",2],["c",3]]') {
+					throw new Error("counts returns " + JSON.stringify([...counts]));
+				}
+			}
+
+			// the keys keep their type and order
+			const names = bridge.names();
+
+			if (JSON.stringify([...names.keys()]) !== "[1,2,10]" || names.get(10) !== "ten" || names.has("10")) {
+				throw new Error("names returns " + JSON.stringify([...names]));
+			}`,
+	})
+}
+
+func TestNullForNil(t *testing.T) {
+	runBridge(t, "nilable", bridgeTest{
+		Script: `
+			for (const f of ["isNil", "isNilMap", "isNilPointer", "isNilInterface"]) {
+				if (!bridge[f](null) || !bridge[f](undefined)) {
+					throw new Error(f + " does not receive null and undefined as nil");
+				}
+			}
+
+			if (bridge.isNil([]) || bridge.isNilMap({}) || bridge.isNilPointer(0) || bridge.isNilInterface(0)) {
+				throw new Error("empty values are received as nil");
+			}
+
+			if (bridge.nil() !== null || bridge.nilMap() !== null) {
+				throw new Error("nil is not returned as null");
+			}`,
+	})
+}
+
+func TestNotRegistered(t *testing.T) {
+	runBridge(t, "frozen", bridgeTest{
+		Go: `defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, ErrNotRegistered) || err.Error() != "Origin: module not registered" {
+			t.Errorf("expected Origin to fail as not registered, got %v", err)
+		}
+	}()
+
+	(&bridgeStruct{}).Origin()`,
+	})
+}
+
+func TestGojaValues(t *testing.T) {
+	runBridge(t, "gojavalues", bridgeTest{
+		Script: `
+			const o = {a: 1, b: [2]};
+
+			// eval is a reserved JS name
+			if (bridge.eval_(o) !== o || bridge.eval_(undefined) !== undefined || bridge.eval_(null) !== null) {
+				throw new Error("eval does not return its argument unchanged");
+			}
+
+			if (bridge.keys(o).join() !== "a,b") {
+				throw new Error("keys returns " + bridge.keys(o));
+			}`,
+	})
+}
+
+func TestChaining(t *testing.T) {
+	runBridge(t, "builder", bridgeTest{
+		Args: []string{"-methods"},
+		Script: `
+			const b = bridge.newBuilder();
+
+			if (b.add(1) !== b || b.add(2).add(3).sum() !== 6 || b.sum() !== 6) {
+				throw new Error("add does not chain on the same builder");
+			}
+
+			b.marker = true;
+			if (!b.add(4).marker) {
+				throw new Error("add returns a fresh object");
+			}
+
+			if (b.add(1).check() !== b || b.add(1).reset() !== undefined || b.sum() !== 0) {
+				throw new Error("check does not chain or reset does not end the chain");
+			}
+
+			let threw = false;
+			try {
+				b.add(-1).check();
+			} catch (e) {
+				threw = true;
+			}
+
+			if (!threw) {
+				throw new Error("check does not throw its error");
+			}`,
+	})
+}
+
+func TestBuiltinShadowing(t *testing.T) {
+	for _, args := range [][]string{nil, {"-methods", "-duration", "ms", "-comma-ok", "-copy-slices"}} {
+		runBridge(t, "builtins", bridgeTest{
+			Args: args,
+			Script: `
+				if (bridge.len([1, 2, 3]) !== 3 || bridge.cap([1]) < 1 || bridge.append([], 7)[0] !== 7) {
+					throw new Error("the builtin shadowing functions return wrong results");
+				}
+
+				bridge.wait(10);
+
+				if (bridge.types(null, null, 0) !== null) {
+					throw new Error("types does not return null");
+				}`,
+		})
+	}
+}
+
+func TestRunes(t *testing.T) {
+	runBridge(t, "runes", bridgeTest{
+		Args: []string{"-runes"},
+		Script: `
+			if (bridge.toUpper("a") !== "A" || bridge.toUpper(97) !== "A" || bridge.toUpper("ä") !== "Ä") {
+				throw new Error("toUpper of a returns " + bridge.toUpper("a"));
+			}
+
+			if (bridge.next("a") !== 98 || bridge.next(1) !== 2) {
+				throw new Error("next of a returns " + bridge.next("a"));
+			}
+
+			for (const wrong of ["ab", "", "€"]) {
+				let threw = false;
+				try {
+					bridge.next(wrong);
+				} catch (e) {
+					threw = true;
+				}
+
+				if (!threw) {
+					throw new Error("next accepts " + wrong);
+				}
+			}`,
+	})
+
+	runBridge(t, "runes", bridgeTest{
+		Script: `
+			if (bridge.toUpper(97) !== 65) {
+				throw new Error("toUpper of 97 returns " + bridge.toUpper(97));
+			}`,
+	})
+}
+
+func TestFunctionalOptions(t *testing.T) {
+	runBridge(t, "options", bridgeTest{
+		Args: []string{"-options"},
+		Script: `
+			const server = bridge.newServer({name: "server", port: 8080});
+
+			if (server.Name !== "server" || server.Port !== 8080) {
+				throw new Error("newServer returns " + JSON.stringify(server));
+			}
+
+			if (bridge.newServer().Port !== 80 || bridge.newServer({name: "x"}).Port !== 80) {
+				throw new Error("newServer does not default the port");
+			}
+
+			let threw = false;
+			try {
+				bridge.newServer({host: "localhost"});
+			} catch (e) {
+				threw = true;
+			}
+
+			if (!threw) {
+				throw new Error("newServer accepts the unknown option host");
+			}`,
+	})
+}
+
+func TestCopySlices(t *testing.T) {
+	runBridge(t, "slices", bridgeTest{
+		Args: []string{"-copy-slices"},
+		Script: `
+			const a = [1, 2, 3];
+			bridge.keep(a);
+
+			a[0] = 100;
+			if (bridge.kept()[0] !== 1) {
+				throw new Error("mutating the passed array changes the Go slice");
+			}
+
+			const r = bridge.kept();
+			r[1] = 50;
+			if (bridge.kept()[1] !== 2) {
+				throw new Error("mutating the returned array changes the Go slice");
+			}
+
+			bridge.double();
+			if (r[2] !== 3 || a[2] !== 3) {
+				throw new Error("mutating the Go slice changes the JS arrays");
+			}`,
+	})
+
+	// without copies the returned array shares the backing array of the Go slice
+	runBridge(t, "slices", bridgeTest{
+		Script: `
+			bridge.keep([1, 2, 3]);
+
+			const r = bridge.kept();
+			r[1] = 50;
+			if (bridge.kept()[1] !== 50) {
+				throw new Error("the returned array is a copy");
+			}`,
+	})
+}
+
+func TestUnimplementableInterface(t *testing.T) {
+	source := generateTestdata(t, "waiters", "-dts")
+
+	assertContains(t, source, "implHandler(")
+
+	if strings.Contains(source, "implDoner(") {
+		t.Error("Doner is implemented by JS objects despite the anonymous struct type of Done")
+	}
+
+	data, err := analyzeTestdata(t, "waiters")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Skip{Kind: "interface", Name: "Doner", Reason: "not implementable by JS objects: method Done has anonymous struct type", Position: "waiters.go:9:6"}
+	if len(data.Skipped) != 1 || data.Skipped[0] != want {
+		t.Errorf("expected the skipped %v, got %v", want, data.Skipped)
+	}
+
+	runBridge(t, "waiters", bridgeTest{
+		Script: `
+			if (bridge.call({handle: name => "hello " + name}, "goja") !== "hello goja") {
+				throw new Error("call does not call the JS handler");
+			}
+
+			if (!bridge.closed(bridge.newDoner())) {
+				throw new Error("closed does not take the Go doner");
+			}`,
+	})
+}
+
+func TestArrayBufferBuilds(t *testing.T) {
+	// zero-copy converts without the bytes package
+	for _, mode := range []string{"copy", "zero-copy"} {
+		buildBridges(t, "crypto/sha256,encoding/base64", "-array-buffer", mode)
+	}
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/mpetavy/common"
+)
+
+// Diagnostic is an error, warning or skipped symbol printed as JSON line to stderr by -format json
+type Diagnostic struct {
+	Level    string `json:"level"`
+	Message  string `json:"message,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Position string `json:"position,omitempty"`
+}
+
+// positionRegexp matches the file:line:column prefix of errors of the parser and type checker
+var positionRegexp = regexp.MustCompile(`^(\S+?\.go:\d+(?::\d+)?): (.*)$`)
+
+func jsonDiagnostics() bool {
+	return *diagFormat == "json"
+}
+
+// silenceLogs drops the free-form log output of common, which would mix with the JSON lines
+func silenceLogs() {
+	common.LogInfo.SetOutput(io.Discard)
+	common.LogWarn.SetOutput(io.Discard)
+	common.LogError.SetOutput(io.Discard)
+}
+
+func diagnose(d Diagnostic) {
+	ba, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+
+	warnMu.Lock()
+	defer warnMu.Unlock()
+
+	_, _ = os.Stderr.Write(append(ba, '\n'))
+}
+
+// diagnoseError prints err with the position its message starts with
+func diagnoseError(err error) {
+	d := Diagnostic{
+		Level:   "error",
+		Message: err.Error(),
+	}
+
+	if m := positionRegexp.FindStringSubmatch(d.Message); m != nil {
+		d.Position = m[1]
+		d.Message = m[2]
+	}
+
+	diagnose(d)
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mpetavy/common"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// splitVersion splits the version off a package name given as pkg@version
+func splitVersion(name string) (string, string) {
+	name, version, _ := strings.Cut(name, "@")
+
+	return name, version
+}
+
+// findVersionedPackagePath returns the directory of the package name in the module cache in
+// the given version independent of the go.mod, downloading its module if it is missing
+func findVersionedPackagePath(name string, version string) (string, string, error) {
+	gomodcache, err := goEnv("GOMODCACHE")
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	// the module is the longest prefix of the package name which exists in the version
+	var errs []string
+
+	for modPath := name; modPath != "." && modPath != "/"; modPath = path.Dir(modPath) {
+		sub := strings.TrimPrefix(strings.TrimPrefix(name, modPath), "/")
+
+		dir, err := cachedModuleDir(gomodcache, modPath, version)
+		if err != nil {
+			errs = append(errs, err.Error())
+
+			continue
+		}
+
+		pathVersion := filepath.Join(dir, filepath.FromSlash(sub))
+		if !common.FileExists(pathVersion) {
+			return "", "", fmt.Errorf("package %s does not exist in module %s@%s", name, modPath, version)
+		}
+
+		return pathVersion, filepath.Join(gomodcache, modPath, filepath.FromSlash(sub)), nil
+	}
+
+	return "", "", fmt.Errorf("cannot download %s@%s: %s", name, version, strings.Join(errs, "; "))
+}
+
+// cachedModuleDir returns the directory of the module modPath in version in the module cache,
+// running go mod download if it is not in the cache yet or version is a query like latest
+func cachedModuleDir(gomodcache string, modPath string, version string) (string, error) {
+	if semver.IsValid(version) {
+		escapedPath, err := module.EscapePath(modPath)
+		if err != nil {
+			return "", err
+		}
+
+		escapedVersion, err := module.EscapeVersion(version)
+		if err != nil {
+			return "", err
+		}
+
+		dir := filepath.Join(gomodcache, escapedPath+"@"+escapedVersion)
+		if common.FileExists(dir) {
+			return dir, nil
+		}
+	}
+
+	common.Info("downloading %s@%s", modPath, version)
+
+	cmd := exec.Command("go", "mod", "download", "-json", modPath+"@"+version)
+	// independent of the go.mod of the working directory
+	cmd.Dir = os.TempDir()
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod")
+
+	stdout, err := cmd.Output()
+
+	download := struct {
+		Dir   string
+		Error string
+	}{}
+
+	if jsonErr := json.Unmarshal(bytes.TrimSpace(stdout), &download); jsonErr != nil {
+		if err != nil {
+			return "", err
+		}
+
+		return "", jsonErr
+	}
+
+	if download.Error != "" {
+		return "", fmt.Errorf("%s", download.Error)
+	}
+
+	return download.Dir, nil
+}
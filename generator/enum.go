@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+)
+
+// enumType returns the exported type declared by the package of the constant name of spec and
+// its underlying integer or string type, empty if the constant is of no such type. prev is the
+// spec an implicitly repeated type and value are taken from.
+func (data *Data) enumType(spec *ast.ValueSpec, prev *ast.ValueSpec, name *ast.Ident) (string, string) {
+	typeName := ""
+	underlying := ""
+
+	var c *types.Const
+	if data.info != nil {
+		c, _ = data.info.Defs[name].(*types.Const)
+	}
+
+	if c != nil {
+		named, ok := c.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != data.pkgPath {
+			return "", ""
+		}
+
+		if basic, ok := named.Underlying().(*types.Basic); ok {
+			typeName, underlying = named.Obj().Name(), basic.Name()
+		}
+	} else {
+		typ := spec.Type
+		if typ == nil && len(spec.Values) == 0 && prev != nil {
+			typ = prev.Type
+		}
+
+		ident, ok := typ.(*ast.Ident)
+		if !ok {
+			return "", ""
+		}
+
+		if ts, ok := data.types[ident.Name]; ok && !ts.Assign.IsValid() {
+			if basic, ok := ts.Type.(*ast.Ident); ok {
+				typeName, underlying = ident.Name, basic.Name
+			}
+		}
+	}
+
+	basic, ok := types.Universe.Lookup(underlying).(*types.TypeName)
+	if !ok || !ast.IsExported(typeName) {
+		return "", ""
+	}
+
+	if info := basic.Type().(*types.Basic).Info(); info&(types.IsInteger|types.IsString) == 0 {
+		return "", ""
+	}
+
+	return typeName, underlying
+}
+
+// addEnumValue adds the bridged constant value to the enum of its type
+func (data *Data) addEnumValue(typeName string, underlying string, value Value) {
+	index := slices.IndexFunc(data.Enums, func(enum Enum) bool {
+		return enum.Name == typeName
+	})
+
+	if index == -1 {
+		data.Enums = append(data.Enums, Enum{
+			Name:    typeName,
+			Numeric: underlying != "string",
+		})
+
+		index = len(data.Enums) - 1
+	}
+
+	data.Enums[index].Values = append(data.Enums[index].Values, EnumValue{
+		Name: value.Name,
+		Expr: underlying + "(" + value.Expr + ")",
+	})
+}
+
+// scanEnums keeps the enums of more than one constant whose names are not taken by bridged
+// functions, types or values
+func (data *Data) scanEnums() {
+	taken := func(name string) bool {
+		if slices.ContainsFunc(data.Funcs, func(f Func) bool { return f.JsName == name || f.JsName+"Async" == name && f.Async != nil }) {
+			return true
+		}
+
+		if slices.ContainsFunc(data.Types, func(t Type) bool { return t.Name == name }) {
+			return true
+		}
+
+		for _, group := range data.Values {
+			if slices.ContainsFunc(group, func(v Value) bool { return v.Name == name }) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	data.Enums = slices.DeleteFunc(data.Enums, func(enum Enum) bool {
+		if len(enum.Values) < 2 {
+			return true
+		}
+
+		if taken(enum.Name) {
+			data.skip("enum", enum.Name, "name taken by another bridged symbol", nil)
+
+			return true
+		}
+
+		return false
+	})
+
+	if len(data.Enums) > 0 {
+		data.useHelper("enum")
+	}
+}
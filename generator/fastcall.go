@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fastArg returns the conversion of the i-th JS argument to the wrapper parameter type typ
+// without reflection like goja converts it, empty if typ needs reflection
+func fastArg(typ string, i int) string {
+	switch typ {
+	case "goja.Value":
+		return fmt.Sprintf("bridgeArg(call, %d)", i)
+	case "string":
+		return fmt.Sprintf("bridgeArgString(call, %d)", i)
+	case "bool":
+		return fmt.Sprintf("call.Argument(%d).ToBoolean()", i)
+	case "int64":
+		return fmt.Sprintf("call.Argument(%d).ToInteger()", i)
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return fmt.Sprintf("%s(call.Argument(%d).ToInteger())", typ, i)
+	case "float64":
+		return fmt.Sprintf("bridgeArgFloat(call, %d)", i)
+	case "float32":
+		return fmt.Sprintf("float32(bridgeArgFloat(call, %d))", i)
+	}
+
+	return ""
+}
+
+// fastCall returns the statement calling the wrapper by call and converting its results to a
+// goja.Value, empty if they need reflection
+func fastCall(call string, results string) string {
+	switch results {
+	case "":
+		return call
+	case "goja.Value":
+		return fmt.Sprintf("bridgeResult(%s)", call)
+	}
+
+	if strings.ContainsAny(results, "(,") || fastArg(results, 0) == "" {
+		return ""
+	}
+
+	// ToValue converts basic types by a type switch
+	return fmt.Sprintf("bridge.vm.ToValue(%s)", call)
+}
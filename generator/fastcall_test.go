@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fastcallsPkg is the fixture of the runtime module whose bridge is generated with -fast-calls
+// and -tests, which benchmarks the reflective against the fast calls of its functions with goja
+const fastcallsPkg = "github.com/mpetavy/goja_go/runtime/internal/fastcalls"
+
+// TestFastCallsBenchmarkUpToDate asserts that the committed bridge of the fastcalls fixture is
+// generated by the current generator. It is regenerated in the repository root by
+//
+//	go run . -g runtime/go.mod -n github.com/mpetavy/goja_go/runtime/internal/fastcalls -o runtime/internal/fastcalls -allow-internal -fast-calls -tests
+//
+// and benchmarked in the runtime module by go test -bench . ./internal/fastcalls/...
+func TestFastCallsBenchmarkUpToDate(t *testing.T) {
+	committed := filepath.Join("..", "runtime", "internal", "fastcalls")
+
+	// the internal package can only be bridged into the runtime module
+	dir, err := os.MkdirTemp(filepath.Join("..", "runtime"), "fastcalls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	parseTestFlags(t, "-g", filepath.Join("..", "runtime", "go.mod"), "-n", fastcallsPkg, "-o", dir, "-allow-internal", "-fast-calls", "-tests", "-cache=false")
+
+	err = runAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputPkg := getPackageName(fastcallsPkg)
+
+	for _, name := range []string{outputPkg + ".go", outputPkg + "_test.go"} {
+		generated, err := os.ReadFile(filepath.Join(*output, outputPkg, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ba, err := os.ReadFile(filepath.Join(committed, outputPkg, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the stamp covers the generator binary
+		_, generatedSource, _ := strings.Cut(string(generated), "\n")
+		_, committedSource, _ := strings.Cut(string(ba), "\n")
+
+		if generatedSource != committedSource {
+			t.Errorf("%s is outdated, regenerate it", name)
+		}
+
+		assertContains(t, generatedSource, "fastAdd")
+	}
+}
@@ -0,0 +1,3364 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mpetavy/common"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
+)
+
+var (
+	allowDenied   = flag.String("allow-denied", "", "comma separated packages allowed to be bridged despite the -denylist")
+	allowInternal = flag.Bool("allow-internal", false, "allow bridging and importing internal packages")
+	arrayBuffer   = flag.String("array-buffer", "", "convert []byte parameters and results from and to JS ArrayBuffers by \"copy\" or sharing their memory with \"zero-copy\"")
+	async         = flag.String("async", "", "regular expression of the functions to also bridge as async variant returning a promise, besides those annotated by //goja:async")
+	bytesAsHex    = flag.Bool("bytes-as-hex", false, "convert []byte and [N]byte parameters and results from and to hex strings")
+	cache         = flag.Bool("cache", true, "cache the scanned packages in the user cache directory to skip parsing unchanged packages")
+	collisions    = flag.String("collisions", "error", "\"error\" about bridged symbols with the same JS name or \"suffix\" the later ones by Go name with _2, _3, ...")
+	commaOk       = flag.Bool("comma-ok", false, "return undefined instead of the value for (T, bool) results if the bool is false")
+	configFile    = flag.String("config", "", "YAML or JSON file listing the packages to generate with their flags, renames and include and exclude filters")
+	copySlices    = flag.Bool("copy-slices", false, "copy slice parameters and results so Go and JS never share their backing arrays")
+	denylist      = flag.String("denylist", "warn", "\"refuse\" or \"warn\" about bridging packages giving scripts access to the system like os, os/exec, net and syscall, off if empty")
+	diagFormat    = flag.String("format", "text", "print errors, warnings and skipped symbols as \"text\" log or as \"json\" lines to stderr")
+	dryRun        = flag.Bool("dry-run", false, "print the functions, types, constants and variables that would be bridged and the skipped ones without writing anything")
+	dts           = flag.Bool("dts", false, "write TypeScript declarations of the bridge next to the generated file")
+	duration      = flag.String("duration", "", "convert time.Duration from and to JS as \"ms\" numbers, duration \"string\"s or ISO-8601 \"iso\" strings")
+	errorMode     = flag.String("errors", "throw", "return a trailing error result by \"throw\"ing it as JS exception or as last element of a \"tuple\" array")
+	exclude       = flag.String("exclude", "", "regular expression of the functions, methods (T.M), constants and variables not to bridge")
+	exportTmpl    = flag.String("export-template", "", "write the built-in template to the given file for customization and exit")
+	fastCalls     = flag.Bool("fast-calls", false, "register functions with parameters and results of basic types by wrappers taking a goja.FunctionCall, which goja calls without reflection")
+	freeze        = flag.Bool("freeze", false, "return structs as frozen JS objects with read-only fields")
+	goarch        = flag.String("goarch", "", "GOARCH of the build context selecting the files to scan, the current one if empty")
+	goos          = flag.String("goos", "", "GOOS of the build context selecting the files to scan, the current one if empty")
+	gojaImport    = flag.String("goja-import", "github.com/dop251/goja", "import path of the goja package used by the generated code")
+	gomodFile     = flag.String("g", "", "path to go.mod file (searched from the working directory if empty, GOPATH mode if none is found)")
+	hashedNames   = flag.Bool("hashed-names", false, "append a hash of the bridged package version and functions to the generated filename")
+	hook          = flag.String("hook", "", "command run before rendering each package, reading the package data as JSON from stdin and writing the data to render as JSON to stdout")
+	headerFile    = flag.String("header-file", "", "file with comment lines or build constraints prepended to every generated file")
+	include       = flag.String("include", "", "regular expression of the functions, methods (T.M), constants and variables to bridge, all if empty")
+	int64Mode     = flag.String("int64", "number", "convert int64 and uint64 from and to JS as \"number\"s losing precision beyond 2^53, as \"bigint\"s or as decimal \"string\"s")
+	interrupt     = flag.Bool("interrupt", false, "pass an interrupt channel to the bridge which every wrapper checks before and after the Go call, interrupting the runtime once it is closed to enforce execution budgets")
+	ir            = flag.String("ir", "", "file to write the scanned packages to as JSON for other tools")
+	instantiate   = flag.String("instantiate", "", "semicolon separated instantiations of generic functions to bridge (e.g. \"Map[int, string];Sum[float64]\")")
+	index         = flag.String("index", "", "JS namespace of an index file registering all generated packages")
+	manifest      = flag.String("manifest", "", "file listing the only symbols allowed to be bridged")
+	mapReturn     = flag.String("map-return", "", "return Go maps as plain JS \"object\"s or JS \"map\"s")
+	multiReturn   = flag.String("multi-return", "array", "return multiple non-error results as JS \"array\" or as \"object\" keyed by the result names")
+	naming        = flag.String("naming", "camelCase", "JS names of functions, methods and fields in \"camelCase\", \"snake_case\" or the Go names by \"keep-go-name\", suffixed by _ if reserved in JS")
+	methodsFlag   = flag.Bool("methods", false, "bridge the methods of exported types returned by or passed to bridged functions")
+	metrics       = flag.Bool("metrics", false, "instrument the generated wrappers with a metrics hook provided at registration")
+	metricsHook   = flag.String("metrics-hook", "", "qualified type of the metrics hook interface (e.g. example.com/metrics.Hook), generated if empty")
+	options       = flag.Bool("options", false, "accept a JS object for variadic functional options and set the fields of the config they mutate")
+	properties    = flag.Bool("properties", false, "bridge the X() or GetX() and SetX(v) method pairs of types as JS accessor properties instead of methods, per type by the properties of the config file")
+	pkgName       = flag.String("n", "", "comma separated package names optionally with @version downloaded independent of the go.mod, a name ending with /... selects the package and all its subpackages registered together by an index file")
+	parallelism   = flag.Int("parallel", runtime.NumCPU(), "number of files parsed and packages generated in parallel")
+	output        = flag.String("o", "", "target directory of the generated package, stdout if \"-\"")
+	recursive     = flag.Bool("recursive", false, "also generate bridges for the non standard library packages used in bridged signatures")
+	recoverFlag   = flag.Bool("recover", true, "convert panics of the bridged functions into JS exceptions with the Go stack attached")
+	prefix        = flag.String("p", "goja_go_", "target package name prefix")
+	profilesFlag  = flag.Bool("profiles", true, "specialize the bridges of packages by their built-in profiles, like net/http by a promise based fetch()")
+	stub          = flag.Bool("stub", false, "generate a stub bridge calling Go callbacks provided at registration instead of the package functions")
+	timeAsDate    = flag.Bool("time-as-date", false, "convert time.Time parameters and results from and to JS Date objects")
+	tmpl          = flag.String("t", "", "template file or directory of templates replacing the blocks of the built-in template named like them (header, function, async, struct, interface, helpers, const, enum, register, dts, test), the built-in template if empty")
+	tags          = flag.String("tags", "", "comma separated build tags of the build context selecting the files to scan")
+	tests         = flag.Bool("tests", false, "write a _test.go next to the generated file calling each bridged function with the zero values of its parameters to catch conversions broken by changes of the bridged package")
+	runes         = flag.Bool("runes", false, "accept single character strings for rune and byte parameters and return runes as strings")
+	sharedRuntime = flag.Bool("shared-runtime", false, "import the conversion helpers from the package "+runtimeImport+" instead of generating them into every bridge")
+	split         = flag.Bool("split", false, "write the wrappers of the functions and types of each source file into a file named after it")
+	requirePrefix = flag.String("require", "", "also register the bridge as goja_nodejs native module named by this prefix and the package path (e.g. \"go/\" for require(\"go/strings\"))")
+	report        = flag.String("report", "", "write a bridge coverage report in the given format (json)")
+	reportFile    = flag.String("report-file", "", "file of the coverage report, next to the generated file if empty")
+	verify        = flag.String("verify", "", "build the generated package and \"report\" the bridged functions failing to compile or \"exclude\" them by regenerating")
+	watch         = flag.Bool("watch", false, "regenerate whenever the bridged packages, the template, the header, the manifest or the config file change")
+	writeManifest = flag.String("write-manifest", "", "file to write all discovered symbols to as a manifest template")
+
+	// deniedPackages give scripts access to the system, which untrusted scripts must not get
+	deniedPackages = []string{"golang.org/x/sys", "io/ioutil", "net", "os", "plugin", "runtime/debug", "syscall", "unsafe"}
+	// parsingPackages are subpackages of deniedPackages which only parse and format values
+	parsingPackages = []string{"net/mail", "net/netip", "net/url"}
+
+	manifestSymbols map[string]bool
+	includes        []*regexp.Regexp
+	excludes        []*regexp.Regexp
+	asyncs          []*regexp.Regexp
+
+	// cached across the packages of a run
+	gomod          *modfile.File
+	goEnvs         = make(map[string]string)
+	vendorPackages map[string]string
+
+	// subcommand is "list" printing the API surface instead of generating, "check" comparing
+	// the generated files to those on disk or "clean" removing the generated files
+	subcommand string
+)
+
+type Func struct {
+	File       string
+	Name       string
+	Type       string
+	Callee     string
+	TsParams   string
+	TsResult   string
+	JsName     string
+	Receiver   string
+	Signature  string
+	Params     string
+	ParamNames string
+	Results    string
+	Returns    string
+	Before     []string
+	After      []string
+	Doc        []string
+	Async      *AsyncFunc
+	// TestArgs are the JS arguments the generated test calls the function with
+	TestArgs string
+	// FastCall calls the wrapper with the arguments of a goja.FunctionCall converted without
+	// reflection by -fast-calls, empty if a parameter or result needs reflection
+	FastCall string
+}
+
+// AsyncFunc is the async variant of a function, which converts the parameters on the event loop,
+// calls the function on a goroutine and settles the promise with the converted results back on
+// the event loop
+type AsyncFunc struct {
+	Before []string
+	Vars   []string
+	Run    []string
+	Settle []string
+}
+
+type Data struct {
+	Filename     string
+	InputPkg     string
+	OutputPkg    string
+	StructName   string
+	JsStructName string
+	ImportPaths  []string
+	Imports      []string
+	// ImportAliases are the aliases of the imports named like another package of the generated
+	// code, imported besides Imports
+	ImportAliases map[string]string
+	Funcs         []Func
+	Types         []Type
+	Interfaces    []Type
+	Values        [][]Value
+	Enums         []Enum
+	Helpers       map[string]bool
+	Runtime       int // version of the shared runtime package providing the helpers, 0 if generated
+	MetricsHook   string
+	Interrupt     bool
+	Profile       string // built-in profile specializing the bridge of the package, if any
+	Stub          bool
+	Require       string
+	Split         bool
+	Skipped       []Skip
+	Extra         map[string]any // data added by the -hook command for custom templates
+	fset          *token.FileSet
+	buildCtx      *build.Context
+	symbols       []string
+	warnings      []string
+	pkgPath       string
+	deps          []string
+	instances     map[string][][]ast.Expr
+	info          *types.Info
+	typeArgs      map[string]ast.Expr
+	instance      string
+	types         map[string]*ast.TypeSpec
+	values        []valueDecl
+	methods       map[string][]*ast.FuncDecl
+	implemented   map[string]bool
+	// importNames are the names the generated code refers to imported packages by, if not the
+	// last element of their path
+	importNames map[string]string
+}
+
+type Type struct {
+	File        string
+	Name        string
+	Type        string
+	Constructor string
+	TsParams    string
+	Methods     []Func
+	Fields      []Field
+	Properties  []Property
+	// Stringer is set if the type implements fmt.Stringer, which the JS toString calls
+	Stringer bool
+}
+
+type Field struct {
+	Name   string
+	JsName string
+	Get    string
+	Ts     string
+}
+
+type Skip struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+	Position string `json:"position,omitempty"`
+}
+
+type valueDecl struct {
+	tok  token.Token
+	spec *ast.ValueSpec
+	decl *ast.GenDecl
+}
+
+type Value struct {
+	Name  string
+	Const bool
+	Ts    string
+	// Expr is the Go expression of the value passed to goja
+	Expr string
+}
+
+// Enum groups the constants of a type declared by the package, which are bridged as frozen
+// object as well
+type Enum struct {
+	Name    string
+	Numeric bool
+	Values  []EnumValue
+}
+
+type EnumValue struct {
+	Name string
+	// Expr converts the constant to its underlying type, so it is not formatted by a String method
+	Expr string
+}
+
+type Index struct {
+	OutputPkg    string
+	Namespace    string
+	Imports      []string
+	Packages     []*Data
+	Declarations []string
+	MetricsHook  string
+	Interrupt    bool
+	Require      bool
+}
+
+// stdout is the -o value writing the generated source to stdout
+const stdout = "-"
+
+// requireImport is the import path of the goja_nodejs module registry used with -require
+const requireImport = "github.com/dop251/goja_nodejs/require"
+
+//go:embed goja_go.tmpl
+var defaultTmpl string
+
+func filter(info os.FileInfo) bool {
+	name := info.Name()
+
+	if info.IsDir() {
+		return false
+	}
+
+	if name == *output {
+		return false
+	}
+
+	if filepath.Ext(name) != ".go" {
+		return false
+	}
+
+	if strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+
+	return true
+}
+
+func upper1st(s string) string {
+	if s == "" {
+		return s
+	}
+
+	rs := []rune(s)
+	rs[0] = unicode.ToUpper(rs[0])
+
+	return string(rs)
+}
+
+func lower1st(s string) string {
+	if s == "" {
+		return s
+	}
+
+	rs := []rune(s)
+	rs[0] = unicode.ToLower(rs[0])
+
+	return string(rs)
+}
+
+// snakeCase separates the words of the Go identifier s by underscores in lower case, keeping
+// acronyms together like in parse_url for ParseURL
+func snakeCase(s string) string {
+	rs := []rune(s)
+	sb := strings.Builder{}
+
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) && rs[i-1] != '_' {
+			prevLower := unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1])
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+
+			if prevLower || (unicode.IsUpper(rs[i-1]) && nextLower) {
+				sb.WriteRune('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
+
+// jsName returns the JS name of the Go identifier name by the -naming strategy, avoiding JS
+// reserved words
+func jsName(name string) string {
+	switch *naming {
+	case "snake_case":
+		name = snakeCase(name)
+	case "camelCase":
+		name = lower1st(name)
+	}
+
+	return tsName(name)
+}
+
+// camelCase joins the words of s separated by underscores, dashes, dots or spaces in lower camel case
+func camelCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+
+	for i, word := range words {
+		if i == 0 {
+			words[i] = lower1st(word)
+		} else {
+			words[i] = upper1st(word)
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+func (data *Data) formatType(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case nil:
+		return ""
+	case *ast.Ident:
+		if arg, ok := data.typeArgs[t.Name]; ok {
+			typeArgs := data.typeArgs
+			data.typeArgs = nil
+			defer func() {
+				data.typeArgs = typeArgs
+			}()
+
+			return data.formatType(arg)
+		}
+
+		if !strings.Contains(t.Name, ".") && t.IsExported() {
+			data.addInputImport()
+
+			return data.InputPkg + "." + t.Name
+		} else {
+			return t.Name
+		}
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if pkg := data.importedPackage(x); pkg != nil {
+				data.addImportPath(pkg.Path())
+				data.nameImport(pkg.Path(), pkg.Name())
+				data.addDep(pkg.Path())
+
+				return fmt.Sprintf("%s.%s", pkg.Name(), t.Sel.Name)
+			}
+
+			data.addImport(x.Name)
+			data.addDep(data.resolveImport(x.Name))
+
+			return fmt.Sprintf("%s.%s", x.Name, t.Sel.Name)
+		}
+
+		return fmt.Sprintf("%s.%s", data.formatType(t.X), t.Sel.Name)
+	case *ast.StarExpr:
+		return fmt.Sprintf("*%s", data.formatType(t.X))
+	case *ast.IndexExpr:
+		return fmt.Sprintf("%s[%s]", data.formatType(t.X), data.formatType(t.Index))
+	case *ast.IndexListExpr:
+		indices := []string{}
+		for _, index := range t.Indices {
+			indices = append(indices, data.formatType(index))
+		}
+
+		return fmt.Sprintf("%s[%s]", data.formatType(t.X), strings.Join(indices, ", "))
+	case *ast.ArrayType:
+		return fmt.Sprintf("[%s]%s", data.formatType(t.Len), data.formatType(t.Elt))
+	case *ast.Ellipsis:
+		return "..." + data.formatType(t.Elt)
+	case *ast.FuncType:
+		return fmt.Sprintf("func(%s)%s", data.formatFuncFields(t.Params, true), data.formatFuncResults(t.Results))
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", data.formatType(t.Key), data.formatType(t.Value))
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return fmt.Sprintf("chan<- %s", data.formatType(t.Value))
+		case ast.RECV:
+			return fmt.Sprintf("<-chan %s", data.formatType(t.Value))
+		default:
+			return fmt.Sprintf("chan %s", data.formatType(t.Value))
+		}
+	case *ast.BasicLit:
+		return t.Value
+	case *ast.InterfaceType:
+		if t.Methods != nil && len(t.Methods.List) > 0 {
+			panic(fmt.Errorf("unsupported interface type %#v", t))
+		}
+
+		return "interface{}"
+	default:
+		panic(fmt.Errorf("unsupported type %#v", t))
+	}
+}
+
+var predeclaredTypes = []string{
+	"any", "bool", "byte", "comparable", "complex64", "complex128", "error", "float32", "float64",
+	"int", "int8", "int16", "int32", "int64", "rune", "string",
+	"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+}
+
+// isStringer reports whether the method decl implements fmt.Stringer
+func isStringer(decl *ast.FuncDecl) bool {
+	if decl.Name.Name != "String" || decl.Type.Params.NumFields() != 0 || decl.Type.Results.NumFields() != 1 {
+		return false
+	}
+
+	ident, ok := decl.Type.Results.List[0].Type.(*ast.Ident)
+
+	return ok && ident.Name == "string"
+}
+
+// unbridgeable returns why the signature of decl cannot be bridged, empty if it can
+func (data *Data) unbridgeable(decl *ast.FuncDecl) string {
+	typeParams := []string{}
+	if decl.Type.TypeParams != nil {
+		for _, field := range decl.Type.TypeParams.List {
+			for _, name := range field.Names {
+				typeParams = append(typeParams, name.Name)
+			}
+		}
+	}
+
+	for _, fields := range []*ast.FieldList{decl.Type.Params, decl.Type.Results} {
+		if fields == nil {
+			continue
+		}
+
+		if reason := data.unbridgeableType(fields); reason != "" {
+			return reason
+		}
+
+		err := data.validateType(fields, typeParams)
+		if err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
+// unbridgeableType returns the type in node which cannot be written in or converted by the
+// generated code, empty if there is none
+func (data *Data) unbridgeableType(node ast.Node) string {
+	reason := ""
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		if reason != "" {
+			return false
+		}
+
+		switch t := node.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok {
+				path := data.resolveImport(x.Name)
+				if pkg := data.importedPackage(x); pkg != nil {
+					path = pkg.Path()
+				}
+
+				switch path {
+				case "C":
+					reason = fmt.Sprintf("cgo type C.%s", t.Sel.Name)
+				case "unsafe":
+					reason = fmt.Sprintf("unsafe type unsafe.%s", t.Sel.Name)
+				}
+			}
+
+			return false
+		case *ast.StructType:
+			reason = "anonymous struct type"
+		case *ast.InterfaceType:
+			if t.Methods != nil && len(t.Methods.List) > 0 {
+				reason = "anonymous interface type with methods"
+			}
+		}
+
+		return true
+	})
+
+	return reason
+}
+
+func (data *Data) validateType(node ast.Node, typeParams []string) error {
+	var err error
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+
+		switch t := n.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Field:
+			err = data.validateType(t.Type, typeParams)
+
+			return false
+		case *ast.ArrayType:
+			err = data.validateType(t.Elt, typeParams)
+
+			return false
+		case *ast.Ident:
+			if slices.Contains(predeclaredTypes, t.Name) || slices.Contains(typeParams, t.Name) {
+				return false
+			}
+
+			if _, ok := data.types[t.Name]; !ok {
+				err = fmt.Errorf("type %s is not declared in package %s", t.Name, data.InputPkg)
+			} else if !t.IsExported() {
+				err = fmt.Errorf("type %s is not exported by package %s", t.Name, data.InputPkg)
+			}
+		}
+
+		return true
+	})
+
+	return err
+}
+
+func (data *Data) formatFuncFields(fields *ast.FieldList, inclType bool) string {
+	s := ""
+	for i, field := range fields.List {
+		for j, name := range field.Names {
+			s += name.Name
+			if j != len(field.Names)-1 {
+				s += ","
+			}
+			s += " "
+		}
+
+		if inclType {
+			s += data.formatType(field.Type)
+		}
+		if i != len(fields.List)-1 {
+			s += ", "
+		}
+	}
+
+	return strings.TrimSpace(s)
+}
+
+func (data *Data) formatFuncResults(fields *ast.FieldList) string {
+	s := ""
+	if fields != nil {
+		named := len(fields.List) == 1 && len(fields.List[0].Names) > 0
+		if len(fields.List) > 1 || named {
+			s += "("
+		}
+
+		f := data.formatFuncFields(fields, true)
+
+		if strings.Contains(f, ",") {
+			f = fmt.Sprintf("(%s)", f)
+		}
+
+		s += f
+
+		if len(fields.List) > 1 || named {
+			s += ")"
+		}
+	}
+
+	s = strings.ReplaceAll(s, "((", "(")
+	s = strings.ReplaceAll(s, "))", ")")
+
+	return s
+}
+
+func (data *Data) formatFuncDecl(decl *ast.FuncDecl) (Func, error) {
+	f := Func{}
+
+	if decl.Recv != nil {
+		if len(decl.Recv.List) != 1 {
+			return f, fmt.Errorf("strange receiver for %s: %#v", decl.Name.Name, decl.Recv)
+		}
+		field := decl.Recv.List[0]
+		if len(field.Names) == 0 {
+			f.Receiver = fmt.Sprintf("(%s) ", data.formatType(field.Type))
+		} else if len(field.Names) != 1 {
+			return f, fmt.Errorf("strange receiver field for %s: %#v", decl.Name.Name, field)
+		} else {
+			f.Receiver = fmt.Sprintf("(%s %s) ", field.Names[0], data.formatType(field.Type))
+		}
+	}
+
+	err := data.checkDeniedSignature(decl)
+	if err != nil {
+		return f, err
+	}
+
+	f.Name = decl.Name.Name
+	f.Callee = data.InputPkg + "." + f.Name
+
+	if decl.Recv == nil && !data.Stub {
+		data.addInputImport()
+	}
+
+	if data.instance != "" {
+		f.Name += "_" + strings.Trim(regexp.MustCompile("[^A-Za-z0-9]+").ReplaceAllString(data.instance, "_"), "_")
+		f.Callee += data.instance
+	}
+
+	f.JsName = jsName(f.Name)
+
+	if decl.Doc != nil {
+		f.Doc = strings.Split(strings.TrimRight(decl.Doc.Text(), "\n"), "\n")
+	}
+
+	name := f.Name
+	if typeName, _, ok := receiverType(decl); ok {
+		f.Type = typeName
+		f.Callee = "bridgeRecv." + f.Name
+		name = typeName + "." + f.Name
+	}
+
+	if jsName, ok := renames[name]; ok {
+		f.JsName = jsName
+	}
+
+	f.Results = data.formatFuncResults(decl.Type.Results)
+	f.Returns = common.Eval(f.Results != "", "return", "")
+
+	params := []string{}
+	paramNames := []string{}
+	paramTypes := []string{}
+	tsParams := []string{}
+	testArgs := []string{}
+	fastArgs := []string{}
+	fast := *fastCalls && decl.Recv == nil
+
+	for i, p := range data.formatParams(decl.Type.Params) {
+		if i == 0 && data.isContext(p.Expr) {
+			data.useHelper("context")
+
+			arg := fmt.Sprintf("bridgeArg%d", i)
+
+			f.Before = append(f.Before, fmt.Sprintf("%s := bridgeContext(bridge.vm)", arg))
+			paramNames = append(paramNames, arg)
+			paramTypes = append(paramTypes, p.Type)
+
+			continue
+		}
+
+		if p.Variadic && !(*options && data.isOptions(p.Expr)) {
+			fast = false
+
+			tsParams = append(tsParams, fmt.Sprintf("...%s: %s", tsName(p.Name), tsArray(data.tsParam(p.elem()))))
+
+			typ, arg := data.convertVariadic(&f, i, p)
+
+			params = append(params, fmt.Sprintf("%s %s", p.Name, typ))
+			paramNames = append(paramNames, arg)
+			paramTypes = append(paramTypes, "..."+p.Type)
+
+			continue
+		}
+
+		tsParams = append(tsParams, fmt.Sprintf("%s: %s", tsName(p.Name), data.tsParam(p)))
+
+		if !p.Variadic {
+			testArgs = append(testArgs, data.testArg(p))
+		}
+
+		typ, arg := data.convertParam(&f, i, p)
+
+		if *copySlices && data.isSlice(p.Expr) {
+			data.addImportPath("slices")
+
+			arg = fmt.Sprintf("slices.Clone(%s)", arg)
+		}
+
+		if fast {
+			fastArgs = append(fastArgs, fastArg(typ, len(params)))
+			fast = !p.Variadic && !slices.Contains(fastArgs, "")
+		}
+
+		params = append(params, fmt.Sprintf("%s %s", p.Name, typ))
+		paramNames = append(paramNames, arg)
+
+		if p.Variadic {
+			paramTypes = append(paramTypes, "..."+p.Type)
+		} else {
+			paramTypes = append(paramTypes, p.Type)
+		}
+	}
+
+	f.Params = fmt.Sprintf("(%s)", strings.Join(params, ", "))
+	f.ParamNames = fmt.Sprintf("(%s)", strings.Join(paramNames, ", "))
+	f.Signature = fmt.Sprintf("func(%s) %s", strings.Join(paramTypes, ", "), f.Results)
+	f.TsParams = strings.Join(tsParams, ", ")
+	f.TestArgs = strings.Join(testArgs, ", ")
+
+	results := data.formatResults(decl.Type.Results)
+
+	f.TsResult = data.tsResults(results)
+
+	data.convertResults(&f, results)
+
+	// the receiver is compared before the final return of the converted result, if any
+	if len(f.After) > 0 && data.isChainable(decl, results) {
+		f.TsResult = "this"
+		f.After = slices.Insert(f.After, len(f.After)-1, "if bridgeRes0 == bridgeRecv {\nreturn bridgeObj\n}")
+	}
+
+	if fast {
+		f.FastCall = fastCall(fmt.Sprintf("bridge.%s(%s)", f.Name, strings.Join(fastArgs, ", ")), f.Results)
+		if f.FastCall != "" {
+			data.useHelper("fastcall")
+		}
+	}
+
+	if data.Stub && decl.Recv == nil {
+		data.addImportPath("fmt")
+
+		f.Callee = "bridge.stubs." + f.Name
+		f.Before = append([]string{fmt.Sprintf("if bridge.stubs.%s == nil {\npanic(bridge.vm.NewGoError(fmt.Errorf(\"%s: %%w\", ErrNotStubbed)))\n}", f.Name, f.Name)}, f.Before...)
+	}
+
+	asyncBefore := f.Before
+
+	if data.MetricsHook != "" {
+		f.Before = append([]string{fmt.Sprintf("if bridge.hook != nil {\nbridge.hook.Before(%q)\ndefer func(start time.Time) {\nbridge.hook.After(%q, time.Since(start))\n}(time.Now())\n}", name, name)}, f.Before...)
+	}
+
+	if data.Interrupt {
+		data.useHelper("interrupt")
+
+		f.Before = append([]string{"bridgeInterrupt(bridge.vm, bridge.interrupt)", "defer bridgeInterrupt(bridge.vm, bridge.interrupt)"}, f.Before...)
+		asyncBefore = append([]string{"bridgeInterrupt(bridge.vm, bridge.interrupt)"}, asyncBefore...)
+	}
+
+	if *recoverFlag {
+		data.useHelper("recover")
+
+		f.Before = append([]string{"defer bridgeRecover(bridge.vm)"}, f.Before...)
+		asyncBefore = append([]string{"defer bridgeRecover(bridge.vm)"}, asyncBefore...)
+	}
+
+	if decl.Recv == nil && isAsync(decl) {
+		data.formatAsync(&f, name, results, asyncBefore)
+	}
+
+	if f.Async != nil || strings.Contains(strings.Join(append(f.Before, f.After...), "\n"), "bridge.vm") {
+		data.addImportPath("fmt")
+
+		check := fmt.Sprintf("if bridge.vm == nil {\npanic(fmt.Errorf(\"%s: %%w\", ErrNotRegistered))\n}", name)
+
+		f.Before = append([]string{check}, f.Before...)
+		if f.Async != nil {
+			f.Async.Before = append([]string{check}, f.Async.Before...)
+		}
+	}
+
+	return f, nil
+}
+
+// isAsync returns if an async variant of the function decl is bridged, by -async or by its
+// //goja:async annotation
+func isAsync(decl *ast.FuncDecl) bool {
+	if decl.Doc != nil {
+		for _, comment := range decl.Doc.List {
+			if strings.TrimSpace(comment.Text) == "//goja:async" {
+				return true
+			}
+		}
+	}
+
+	return slices.ContainsFunc(asyncs, func(re *regexp.Regexp) bool {
+		return re.MatchString(decl.Name.Name)
+	})
+}
+
+// formatAsync adds the async variant to f, settling with the results converted by f.After
+func (data *Data) formatAsync(f *Func, name string, results []Param, before []string) {
+	data.useHelper("async")
+
+	async := &AsyncFunc{Before: before}
+
+	names := []string{}
+	for _, result := range results {
+		names = append(names, result.Name)
+		async.Vars = append(async.Vars, fmt.Sprintf("var %s %s", result.Name, result.Type))
+	}
+
+	if data.MetricsHook != "" {
+		async.Run = append(async.Run, fmt.Sprintf("if bridge.hook != nil {\nbridge.hook.Before(%q)\ndefer func(start time.Time) {\nbridge.hook.After(%q, time.Since(start))\n}(time.Now())\n}", name+"Async", name+"Async"))
+	}
+
+	call := f.Callee + f.ParamNames
+	if len(names) > 0 {
+		call = strings.Join(names, ", ") + " = " + call
+	}
+
+	async.Run = append(async.Run, call)
+
+	after := f.After
+	if f.Returns == "return" {
+		after = []string{"return " + strings.Join(names, ", ")}
+	}
+
+	if data.Interrupt {
+		async.Settle = append(async.Settle, "bridgeInterrupt(bridge.vm, bridge.interrupt)")
+	}
+
+	if f.Results == "" {
+		if len(after) > 0 {
+			async.Settle = append(async.Settle, fmt.Sprintf("func() {\n%s\n}()", strings.Join(after, "\n")))
+		}
+
+		async.Settle = append(async.Settle, "return goja.Undefined()")
+	} else {
+		async.Settle = append(async.Settle, fmt.Sprintf("return bridge.vm.ToValue(bridgeResults(func() %s {\n%s\n}()))", f.Results, strings.Join(after, "\n")))
+	}
+
+	f.Async = async
+}
+
+func (data *Data) resolveImport(imprt string) string {
+	for _, df := range data.ImportPaths {
+		if strings.HasSuffix(df, "/"+imprt) {
+			return df
+		}
+	}
+
+	return imprt
+}
+
+// importedPackage returns the package the identifier x of a qualified identifier refers to,
+// if known from type checking
+func (data *Data) importedPackage(x *ast.Ident) *types.Package {
+	if data.info == nil {
+		return nil
+	}
+
+	pkgName, ok := data.info.Uses[x].(*types.PkgName)
+	if !ok {
+		return nil
+	}
+
+	return pkgName.Imported()
+}
+
+func (data *Data) addImport(imprt string) {
+	name := imprt
+	imprt = data.resolveImport(imprt)
+
+	data.nameImport(imprt, name)
+
+	if slices.Contains(data.Imports, imprt) || (!*allowInternal && strings.HasPrefix(imprt, "internal/")) {
+		return
+	}
+
+	data.Imports = append(data.Imports, imprt)
+}
+
+// addDep records a package used in the bridged signatures, which -recursive bridges as well
+func (data *Data) addDep(path string) {
+	first, _, _ := strings.Cut(path, "/")
+
+	if !strings.Contains(first, ".") || path == *gojaImport || slices.Contains(data.deps, path) {
+		return
+	}
+
+	data.deps = append(data.deps, path)
+}
+
+func (data *Data) nameImport(path string, name string) {
+	if data.importNames == nil {
+		data.importNames = make(map[string]string)
+	}
+
+	data.importNames[path] = name
+}
+
+// importName returns the name the generated code refers to the imported package path by
+func (data *Data) importName(path string) string {
+	if name, ok := data.importNames[path]; ok {
+		return name
+	}
+
+	if path == data.pkgPath {
+		return data.InputPkg
+	}
+
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// inputAliasPrefix prefixes the alias of a bridged package named like a package the generated
+// code uses, which no package or helper of the generated code is named with
+const inputAliasPrefix = "bridgepkg_"
+
+// addInputImport imports the bridged package, under its alias besides the generator's own
+// import of the same path if it has one
+func (data *Data) addInputImport() {
+	if !strings.HasPrefix(data.InputPkg, inputAliasPrefix) {
+		data.addImportPath(data.pkgPath)
+
+		return
+	}
+
+	data.ImportAliases = map[string]string{data.pkgPath: data.InputPkg}
+}
+
+func (data *Data) addImportPath(path string) {
+	if !slices.Contains(data.Imports, path) {
+		data.Imports = append(data.Imports, path)
+	}
+}
+
+func (data *Data) scan(files map[string]*ast.File) error {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		for _, decl := range files[filename].Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv != nil && fd.Name.IsExported() {
+				if typeName, _, ok := receiverType(fd); ok && ast.IsExported(typeName) {
+					data.methods[typeName] = append(data.methods[typeName], fd)
+				}
+			}
+
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					data.types[spec.Name.Name] = spec
+				case *ast.ValueSpec:
+					data.values = append(data.values, valueDecl{tok: gd.Tok, spec: spec, decl: gd})
+				}
+			}
+		}
+	}
+
+	// bridged declarations by function name, which later declarations may supersede
+	bridged := make(map[string]*ast.FuncDecl)
+
+	for _, filename := range filenames {
+		file := files[filename]
+
+		for _, i := range file.Imports {
+			if i.Path.Value == "" {
+				continue
+			}
+
+			name := i.Path.Value[1 : len(i.Path.Value)-1]
+
+			data.ImportPaths = append(data.ImportPaths, name)
+		}
+
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if ok && fd.Recv == nil && fd.Name.IsExported() {
+				name := fd.Name.Name
+
+				if !slices.Contains(data.symbols, name) {
+					data.symbols = append(data.symbols, name)
+				}
+
+				if manifestSymbols != nil && !manifestSymbols[name] {
+					data.skip("function", name, "not listed in manifest", fd)
+
+					continue
+				}
+
+				if reason := filtered(name); reason != "" {
+					data.skip("function", name, reason, fd)
+
+					continue
+				}
+
+				if reason := data.unbridgeable(fd); reason != "" {
+					data.skip("function", name, reason, fd)
+
+					continue
+				}
+
+				if fd.Type.TypeParams != nil {
+					if len(data.instances[name]) == 0 {
+						data.skip("function", name, "generic function without -instantiate", fd)
+
+						continue
+					}
+
+					for _, args := range data.instances[name] {
+						f, err := data.formatInstance(fd, args)
+						if common.Error(err) {
+							return err
+						}
+
+						if data.excludedByVerify(f.Name) {
+							data.skip("function", f.Name, "failed to compile", fd)
+
+							continue
+						}
+
+						if data.indexFunc(f.Name) != -1 {
+							data.skip("function", f.Name, "instantiated more than once", fd)
+
+							continue
+						}
+
+						f.File = filename
+
+						data.Funcs = append(data.Funcs, f)
+					}
+
+					continue
+				}
+
+				f, err := data.formatFuncDecl(fd)
+				if common.Error(err) {
+					return err
+				}
+
+				if f.Name == "" {
+					continue
+				}
+
+				if data.excludedByVerify(f.Name) {
+					data.skip("function", f.Name, "failed to compile", fd)
+
+					continue
+				}
+
+				f.File = filename
+
+				index := data.indexFunc(name)
+				if index == -1 {
+					data.Funcs = append(data.Funcs, f)
+					bridged[name] = fd
+
+					continue
+				}
+
+				replace, err := preferFile(data.buildCtx, data.Funcs[index].File, filename)
+				if common.Error(err) {
+					return fmt.Errorf("ambiguous function %s: %v", name, err)
+				}
+
+				if replace {
+					data.skip("function", name, fmt.Sprintf("superseded by declaration in %s", filepath.Base(filename)), bridged[name])
+					data.Funcs[index] = f
+					bridged[name] = fd
+				} else {
+					data.skip("function", name, fmt.Sprintf("superseded by declaration in %s", filepath.Base(data.Funcs[index].File)), fd)
+				}
+			}
+		}
+	}
+
+	data.scanValues()
+
+	if *methodsFlag {
+		err := data.scanMethods()
+		if common.Error(err) {
+			return err
+		}
+	}
+
+	data.scanInterfaces()
+
+	err := data.checkCollisions()
+	if common.Error(err) {
+		return err
+	}
+
+	data.scanEnums()
+
+	return nil
+}
+
+// sortFuncs orders functions by their names, replaced by tests counting the sorts
+var sortFuncs = func(funcs []Func) {
+	sort.Slice(funcs, func(i, j int) bool {
+		return funcs[i].Name < funcs[j].Name
+	})
+}
+
+// finalize orders the collected imports and functions once after all files are scanned
+func (data *Data) finalize() {
+	sort.Strings(data.Imports)
+	sortFuncs(data.Funcs)
+}
+
+func parseInstances(spec string) (map[string][][]ast.Expr, error) {
+	instances := make(map[string][][]ast.Expr)
+
+	for _, s := range strings.Split(spec, ";") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		expr, err := parser.ParseExpr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid instantiation %s: %w", s, err)
+		}
+
+		var x ast.Expr
+		var args []ast.Expr
+
+		switch t := expr.(type) {
+		case *ast.IndexExpr:
+			x, args = t.X, []ast.Expr{t.Index}
+		case *ast.IndexListExpr:
+			x, args = t.X, t.Indices
+		}
+
+		ident, ok := x.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("invalid instantiation %s: expected Func[Type, ...]", s)
+		}
+
+		instances[ident.Name] = append(instances[ident.Name], args)
+	}
+
+	return instances, nil
+}
+
+// formatInstance formats the generic function decl instantiated with the type arguments args
+// as a wrapper named after the function and its type arguments, e.g. Map_int_string
+func (data *Data) formatInstance(decl *ast.FuncDecl, args []ast.Expr) (Func, error) {
+	if decl.Type.TypeParams.NumFields() != len(args) {
+		return Func{}, fmt.Errorf("instantiation of %s has %d type arguments, expected %d", decl.Name.Name, len(args), decl.Type.TypeParams.NumFields())
+	}
+
+	typeArgs := make(map[string]ast.Expr)
+
+	for _, field := range decl.Type.TypeParams.List {
+		for _, name := range field.Names {
+			typeArgs[name.Name] = args[len(typeArgs)]
+		}
+	}
+
+	types := []string{}
+	for _, arg := range args {
+		types = append(types, data.formatType(arg))
+	}
+
+	data.instance = fmt.Sprintf("[%s]", strings.Join(types, ", "))
+	defer func() {
+		data.instance = ""
+	}()
+
+	data.typeArgs = typeArgs
+	defer func() {
+		data.typeArgs = nil
+	}()
+
+	return data.formatFuncDecl(decl)
+}
+
+func receiverType(decl *ast.FuncDecl) (string, bool, bool) {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return "", false, false
+	}
+
+	expr := decl.Recv.List[0].Type
+	star, pointer := expr.(*ast.StarExpr)
+	if pointer {
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false, false
+	}
+
+	return ident.Name, pointer, true
+}
+
+func (data *Data) isBridgedType(name string) bool {
+	if !*methodsFlag || len(data.methods[name]) == 0 {
+		return false
+	}
+
+	spec, ok := data.types[name]
+	if !ok || spec.TypeParams != nil {
+		return false
+	}
+
+	_, isInterface := spec.Type.(*ast.InterfaceType)
+
+	return !isInterface
+}
+
+// isChainable reports whether the method returns its pointer receiver type, optionally with an error
+// thrown as exception, so the wrapper returns the JS object it was called on if the builder returns
+// its receiver
+func (data *Data) isChainable(decl *ast.FuncDecl, results []Param) bool {
+	if len(results) == 2 && results[1].Type == "error" && *errorMode != "tuple" {
+		results = results[:1]
+	}
+
+	typeName, pointer, ok := receiverType(decl)
+	if !ok || !pointer || len(results) != 1 || !data.isBridgedType(typeName) {
+		return false
+	}
+
+	star, ok := results[0].Expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := star.X.(*ast.Ident)
+
+	return ok && ident.Name == typeName
+}
+
+// scanValues collects the exported constants and variables, grouped by their declaration
+func (data *Data) scanValues() {
+	var group *ast.GenDecl
+	var decl *ast.GenDecl
+	var prev *ast.ValueSpec
+
+	for _, value := range data.values {
+		kind := "var"
+		if value.tok == token.CONST {
+			kind = "const"
+		}
+
+		if value.decl != decl {
+			decl = value.decl
+			prev = nil
+		}
+
+		for i, name := range value.spec.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			if !slices.Contains(data.symbols, name.Name) {
+				data.symbols = append(data.symbols, name.Name)
+			} else {
+				continue
+			}
+
+			if manifestSymbols != nil && !manifestSymbols[name.Name] {
+				data.skip(kind, name.Name, "not listed in manifest", name)
+
+				continue
+			}
+
+			if reason := filtered(name.Name); reason != "" {
+				data.skip(kind, name.Name, reason, name)
+
+				continue
+			}
+
+			if value.decl != group {
+				group = value.decl
+				data.Values = append(data.Values, nil)
+			}
+
+			v := Value{
+				Name:  name.Name,
+				Const: value.tok == token.CONST,
+				Ts:    data.tsValue(value.spec, prev, i),
+				Expr:  data.valueExpr(name),
+			}
+
+			data.Values[len(data.Values)-1] = append(data.Values[len(data.Values)-1], v)
+
+			if typeName, underlying := data.enumType(value.spec, prev, name); v.Const && typeName != "" {
+				data.addEnumValue(typeName, underlying, v)
+			}
+		}
+
+		if value.spec.Type != nil || len(value.spec.Values) > 0 {
+			prev = value.spec
+		}
+	}
+
+	if len(data.Values) > 0 {
+		data.addInputImport()
+	}
+}
+
+// valueExpr returns the Go expression of the constant or variable name, converting untyped
+// integer constants not fitting into an int, which goja would otherwise receive as int
+func (data *Data) valueExpr(name *ast.Ident) string {
+	expr := data.InputPkg + "." + name.Name
+
+	if data.info == nil {
+		return expr
+	}
+
+	c, ok := data.info.Defs[name].(*types.Const)
+	if !ok || c.Val().Kind() != constant.Int {
+		return expr
+	}
+
+	if basic, ok := c.Type().(*types.Basic); !ok || basic.Info()&types.IsUntyped == 0 {
+		return expr
+	}
+
+	if _, exact := constant.Int64Val(c.Val()); exact {
+		return expr
+	}
+
+	if _, exact := constant.Uint64Val(c.Val()); exact {
+		return "uint64(" + expr + ")"
+	}
+
+	return "float64(" + expr + ")"
+}
+
+// constructor sets the bridged New<Type> function returning the type as first result, if any
+func (data *Data) constructor(typ *Type) {
+	index := data.indexFunc("New" + typ.Name)
+	if index == -1 {
+		return
+	}
+
+	f := data.Funcs[index]
+	results := strings.TrimPrefix(f.Signature[strings.LastIndex(f.Signature, ")")+1:], " ")
+	if strings.Contains(f.Signature, ") (") {
+		results = f.Signature[strings.Index(f.Signature, ") (")+3:]
+		results, _, _ = strings.Cut(results, ",")
+	}
+
+	if results != typ.Type && results != "*"+typ.Type {
+		return
+	}
+
+	typ.Constructor = f.Name
+	typ.TsParams = f.TsParams
+}
+
+func (data *Data) scanMethods() error {
+	names := []string{}
+	for typeName := range data.methods {
+		names = append(names, typeName)
+	}
+
+	// skip in a stable order
+	sort.Strings(names)
+
+	typeNames := []string{}
+	for _, typeName := range names {
+		if data.isBridgedType(typeName) {
+			typeNames = append(typeNames, typeName)
+
+			continue
+		}
+
+		if spec, ok := data.types[typeName]; ok && spec.TypeParams != nil {
+			for _, fd := range data.methods[typeName] {
+				data.skip("method", typeName+"."+fd.Name.Name, "methods of generic types are not supported", fd)
+			}
+		}
+	}
+
+	for _, typeName := range typeNames {
+		typ := Type{
+			Name: typeName,
+			Type: data.formatType(&ast.Ident{Name: typeName}),
+		}
+
+		if spec, ok := data.types[typeName]; ok && data.fset != nil {
+			typ.File = data.fset.Position(spec.Pos()).Filename
+		}
+
+		typ.Stringer = slices.ContainsFunc(data.methods[typeName], isStringer)
+		if typ.Stringer && *recoverFlag {
+			data.useHelper("recover")
+		}
+
+		for _, fd := range data.methods[typeName] {
+			name := typeName + "." + fd.Name.Name
+
+			if !slices.Contains(data.symbols, name) {
+				data.symbols = append(data.symbols, name)
+			}
+
+			if manifestSymbols != nil && !manifestSymbols[name] {
+				data.skip("method", name, "not listed in manifest", fd)
+
+				continue
+			}
+
+			if reason := filtered(name); reason != "" {
+				data.skip("method", name, reason, fd)
+
+				continue
+			}
+
+			if slices.ContainsFunc(typ.Methods, func(m Func) bool { return m.Name == fd.Name.Name }) {
+				data.skip("method", name, "declared more than once", fd)
+
+				continue
+			}
+
+			if data.excludedByVerify(name) {
+				data.skip("method", name, "failed to compile", fd)
+
+				continue
+			}
+
+			if reason := data.unbridgeable(fd); reason != "" {
+				data.skip("method", name, reason, fd)
+
+				continue
+			}
+
+			f, err := data.formatFuncDecl(fd)
+			if common.Error(err) {
+				return err
+			}
+
+			typ.Methods = append(typ.Methods, f)
+		}
+
+		sort.Slice(typ.Methods, func(i, j int) bool {
+			return typ.Methods[i].Name < typ.Methods[j].Name
+		})
+
+		data.scanProperties(&typ)
+		data.constructor(&typ)
+		data.scanFields(&typ)
+
+		data.Types = append(data.Types, typ)
+	}
+
+	return nil
+}
+
+// scanFields collects the exported fields of the struct type typ, which its JS objects
+// expose as accessor properties reading and writing the fields of the Go value
+func (data *Data) scanFields(typ *Type) {
+	st, ok := data.types[typ.Name].Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			data.useHelper("export")
+
+			get := fmt.Sprintf("bridge.vm.ToValue(bridgeRecv.%s)", name.Name)
+
+			switch typeName, pointer, ok := data.bridgedType(field.Type); {
+			case ok && pointer:
+				get = fmt.Sprintf("bridge.wrap%s(bridgeRecv.%s)", typeName, name.Name)
+			case ok:
+				get = fmt.Sprintf("bridge.wrap%s(&bridgeRecv.%s)", typeName, name.Name)
+			case data.isStruct(field.Type) && !isPointer(field.Type), isStructType(field.Type):
+				get = fmt.Sprintf("bridge.vm.ToValue(&bridgeRecv.%s)", name.Name)
+			}
+
+			typ.Fields = append(typ.Fields, Field{
+				Name:   name.Name,
+				JsName: jsName(name.Name),
+				Get:    get,
+				Ts:     data.tsType(field.Type),
+			})
+		}
+	}
+}
+
+func isPointer(expr ast.Expr) bool {
+	_, ok := expr.(*ast.StarExpr)
+
+	return ok
+}
+
+func isStructType(expr ast.Expr) bool {
+	_, ok := expr.(*ast.StructType)
+
+	return ok
+}
+
+// skip records the symbol as skipped with the position of the node pos, if any
+func (data *Data) skip(kind string, name string, reason string, pos ast.Node) {
+	skip := Skip{
+		Kind:   kind,
+		Name:   name,
+		Reason: reason,
+	}
+
+	if pos != nil && data.fset != nil {
+		// relative to the package directory, which differs between machines
+		position := data.fset.Position(pos.Pos())
+		position.Filename = filepath.Base(position.Filename)
+
+		skip.Position = position.String()
+	}
+
+	data.diagnoseSkip(skip)
+
+	data.Skipped = append(data.Skipped, skip)
+}
+
+func (data *Data) diagnoseSkip(skip Skip) {
+	if jsonDiagnostics() {
+		diagnose(Diagnostic{Level: "skip", Package: data.pkgPath, Kind: skip.Kind, Name: skip.Name, Reason: skip.Reason, Position: skip.Position})
+	} else if !strings.HasPrefix(skip.Reason, "not listed") && !strings.HasPrefix(skip.Reason, "superseded") && !strings.HasPrefix(skip.Reason, "filtered") {
+		warn("skipped %s %s: %s", skip.Kind, skip.Name, skip.Reason)
+	}
+}
+
+// warn logs a warning about the package, which is kept to be repeated if the package is cached
+func (data *Data) warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	data.warnings = append(data.warnings, msg)
+
+	warn("%s", msg)
+}
+
+// replayDiagnostics repeats the warnings and skipped symbols of the scan of a cached package
+func (data *Data) replayDiagnostics() {
+	for _, msg := range data.warnings {
+		warn("%s", msg)
+	}
+
+	for _, skip := range data.Skipped {
+		data.diagnoseSkip(skip)
+	}
+}
+
+func (data *Data) indexFunc(name string) int {
+	for i, f := range data.Funcs {
+		if f.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// buildContext returns the build context of -goos, -goarch and -tags
+func buildContext() build.Context {
+	ctx := build.Default
+
+	if *goos != "" {
+		ctx.GOOS = *goos
+	}
+
+	if *goarch != "" {
+		ctx.GOARCH = *goarch
+	}
+
+	if *tags != "" {
+		ctx.BuildTags = strings.Split(strings.ReplaceAll(*tags, " ", ""), ",")
+	}
+
+	return ctx
+}
+
+func matchFile(ctx *build.Context, filename string) (bool, error) {
+	return ctx.MatchFile(filepath.Dir(filename), filepath.Base(filename))
+}
+
+func preferFile(ctx *build.Context, current string, candidate string) (bool, error) {
+	currentMatch, err := matchFile(ctx, current)
+	if common.Error(err) {
+		return false, err
+	}
+
+	candidateMatch, err := matchFile(ctx, candidate)
+	if common.Error(err) {
+		return false, err
+	}
+
+	if currentMatch == candidateMatch {
+		return false, fmt.Errorf("declared in %s and %s, both files %s the current build context", filepath.Base(current), filepath.Base(candidate), common.Eval(currentMatch, "match", "do not match"))
+	}
+
+	return candidateMatch, nil
+}
+
+func readGomod() (*modfile.File, error) {
+	if gomod != nil {
+		return gomod, nil
+	}
+
+	fi, err := os.Stat(*gomodFile)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		*gomodFile = filepath.Join(*gomodFile, "go.mod")
+	}
+
+	ba, err := os.ReadFile(*gomodFile)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	gomod, err = modfile.Parse(*gomodFile, ba, nil)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	return gomod, nil
+}
+
+func goEnv(name string) (string, error) {
+	goEnvMu.Lock()
+	defer goEnvMu.Unlock()
+
+	if value, ok := goEnvs[name]; ok {
+		return value, nil
+	}
+
+	cmd := exec.Command("go", "env", name)
+	stdout, err := cmd.Output()
+	if common.Error(err) {
+		return "", err
+	}
+
+	goEnvs[name] = strings.TrimSpace(string(stdout))
+
+	return goEnvs[name], nil
+}
+
+func relPath(root string, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return rel, true
+}
+
+func locateGomod() error {
+	if *gomodFile != "" {
+		return nil
+	}
+
+	dir, err := os.Getwd()
+	if common.Error(err) {
+		return err
+	}
+
+	for {
+		filename := filepath.Join(dir, "go.mod")
+
+		if _, err := os.Stat(filename); err == nil {
+			*gomodFile = filename
+
+			return nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+
+		dir = parent
+	}
+}
+
+func gopathSrcs() ([]string, error) {
+	gopath, err := goEnv("GOPATH")
+	if common.Error(err) {
+		return nil, err
+	}
+
+	srcs := []string{}
+	for _, path := range filepath.SplitList(gopath) {
+		srcs = append(srcs, filepath.Join(path, "src"))
+	}
+
+	return srcs, nil
+}
+
+func findGopathPackagePath(name string) (string, string, error) {
+	srcs, err := gopathSrcs()
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	for _, src := range srcs {
+		path := filepath.Join(src, filepath.FromSlash(name))
+
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			return path, path, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unknown package name: %s, no go.mod found and not in GOPATH", name)
+}
+
+func findPackagePath(name string) (string, string, error) {
+	resolveMu.Lock()
+	defer resolveMu.Unlock()
+
+	if name, version := splitVersion(name); version != "" {
+		return findVersionedPackagePath(name, version)
+	}
+
+	err := locateGomod()
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	var gomod *modfile.File
+
+	if *gomodFile != "" {
+		gomod, err = readGomod()
+		if common.Error(err) {
+			return "", "", err
+		}
+
+		if gomod.Module != nil {
+			if sub, ok := modulePackagePath(name, gomod.Module.Mod.Path); ok {
+				path := filepath.Join(filepath.Dir(*gomodFile), sub)
+
+				return path, path, nil
+			}
+		}
+	}
+
+	if isStdlib(name) {
+		return findStdlibPackagePath(name)
+	}
+
+	if gomod == nil {
+		return findGopathPackagePath(name)
+	}
+
+	path, ok, err := findVendorPackagePath(name)
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	if ok {
+		return path, path, nil
+	}
+
+	gomodcache, err := goEnv("GOMODCACHE")
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	for _, r := range gomod.Replace {
+		// a replacement by a module instead of a directory has a version
+		if r.New.Version != "" {
+			sub, ok := modulePackagePath(name, r.Old.Path)
+			if !ok {
+				continue
+			}
+
+			dir, err := cachedModuleDir(gomodcache, r.New.Path, r.New.Version)
+			if err != nil {
+				return "", "", fmt.Errorf("cannot resolve replacement %s of %s: %w", r.New, r.Old.Path, err)
+			}
+
+			return filepath.Join(dir, sub), filepath.Join(gomodcache, r.New.Path, sub), nil
+		}
+
+		if strings.HasPrefix(r.Old.String(), name) {
+			return filepath.Join(filepath.Dir(*gomodFile), r.New.String()), filepath.Join(filepath.Dir(*gomodFile), r.New.Path), nil
+		}
+
+		sub, ok := subPackagePath(name, r.Old.Path)
+		if ok {
+			return filepath.Join(filepath.Dir(*gomodFile), r.New.String(), sub), filepath.Join(filepath.Dir(*gomodFile), r.New.Path, sub), nil
+		}
+	}
+
+	for _, r := range gomod.Require {
+		if strings.HasPrefix(r.Mod.String(), name) {
+			return filepath.Join(string(gomodcache), r.Mod.String()), filepath.Join(string(gomodcache), r.Mod.Path), nil
+		}
+
+		sub, ok := subPackagePath(name, r.Mod.Path)
+		if ok {
+			return filepath.Join(string(gomodcache), r.Mod.String(), sub), filepath.Join(string(gomodcache), r.Mod.Path, sub), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unknown package name: %s", name)
+}
+
+// isStdlib reports whether pkg is a standard library package, whose first path element has no dot
+func isStdlib(pkg string) bool {
+	first, _, _ := strings.Cut(pkg, "/")
+
+	return !strings.Contains(first, ".")
+}
+
+func findStdlibPackagePath(name string) (string, string, error) {
+	goroot, err := goEnv("GOROOT")
+	if common.Error(err) {
+		return "", "", err
+	}
+
+	path := filepath.Join(goroot, "src", filepath.FromSlash(name))
+
+	if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+		return "", "", fmt.Errorf("unknown standard library package: %s", name)
+	}
+
+	return path, path, nil
+}
+
+// readVendor reads the packages listed in vendor/modules.txt next to the go.mod with the versions
+// of their modules, none if the module is not vendored or -mod=mod is set by GOFLAGS
+func readVendor() (map[string]string, error) {
+	if vendorPackages != nil {
+		return vendorPackages, nil
+	}
+
+	vendorPackages = make(map[string]string)
+
+	goflags, err := goEnv("GOFLAGS")
+	if common.Error(err) {
+		return nil, err
+	}
+
+	if slices.Contains(strings.Fields(goflags), "-mod=mod") {
+		return vendorPackages, nil
+	}
+
+	ba, err := os.ReadFile(filepath.Join(filepath.Dir(*gomodFile), "vendor", "modules.txt"))
+	if os.IsNotExist(err) {
+		return vendorPackages, nil
+	}
+
+	if common.Error(err) {
+		return nil, err
+	}
+
+	version := ""
+	for _, line := range strings.Split(string(ba), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "##"):
+		case strings.HasPrefix(line, "#"):
+			version = ""
+			if fields := strings.Fields(line); len(fields) > 2 && fields[2] != "=>" {
+				version = fields[2]
+			}
+		default:
+			vendorPackages[line] = version
+		}
+	}
+
+	return vendorPackages, nil
+}
+
+// vendorVersion returns the version of the module of the vendored package name, if known
+func vendorVersion(name string) string {
+	resolveMu.Lock()
+	defer resolveMu.Unlock()
+
+	return vendorPackages[name]
+}
+
+// findVendorPackagePath returns the directory of the package in the vendor directory, if vendored
+func findVendorPackagePath(name string) (string, bool, error) {
+	packages, err := readVendor()
+	if common.Error(err) {
+		return "", false, err
+	}
+
+	if _, ok := packages[name]; !ok {
+		return "", false, nil
+	}
+
+	return filepath.Join(filepath.Dir(*gomodFile), "vendor", filepath.FromSlash(name)), true, nil
+}
+
+// modulePackagePath returns the directory of the package relative to the module modPath,
+// if it is the module itself or one of its packages
+func modulePackagePath(name string, modPath string) (string, bool) {
+	if name == modPath {
+		return ".", true
+	}
+
+	return subPackagePath(name, modPath)
+}
+
+func subPackagePath(name string, modPath string) (string, bool) {
+	if !strings.HasPrefix(name, modPath+"/") {
+		return "", false
+	}
+
+	return filepath.FromSlash(strings.TrimPrefix(name, modPath+"/")), true
+}
+
+func internalRoot(pkg string) (int, bool) {
+	elems := strings.Split(pkg, "/")
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		if elems[i] == "internal" {
+			return len(elems) - i, true
+		}
+	}
+
+	return 0, false
+}
+
+// isDenied reports whether the package pkg is on the -denylist and not allowed
+func isDenied(pkg string) bool {
+	if *denylist == "" || slices.Contains(parsingPackages, pkg) || slices.Contains(strings.Split(strings.ReplaceAll(*allowDenied, " ", ""), ","), pkg) {
+		return false
+	}
+
+	return slices.ContainsFunc(deniedPackages, func(denied string) bool {
+		return pkg == denied || strings.HasPrefix(pkg, denied+"/")
+	})
+}
+
+func checkDenied(name string) error {
+	if *denylist != "" && *denylist != "refuse" && *denylist != "warn" {
+		return fmt.Errorf("invalid denylist mode: %s", *denylist)
+	}
+
+	pkg, _, _ := strings.Cut(name, "@")
+	if !isDenied(pkg) {
+		return nil
+	}
+
+	if *denylist == "warn" {
+		warn("package %s gives scripts access to the system, allow it with -allow-denied", pkg)
+
+		return nil
+	}
+
+	return fmt.Errorf("package %s gives scripts access to the system and can only be bridged with -allow-denied", pkg)
+}
+
+// checkDeniedSignature refuses or warns about bridging decl if its signature passes
+// values of a package on the -denylist between Go and the scripts
+func (data *Data) checkDeniedSignature(decl *ast.FuncDecl) error {
+	pkg := ""
+
+	ast.Inspect(decl.Type, func(node ast.Node) bool {
+		if pkg != "" {
+			return false
+		}
+
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if x, ok := sel.X.(*ast.Ident); ok {
+			path := data.resolveImport(x.Name)
+			if p := data.importedPackage(x); p != nil {
+				path = p.Path()
+			}
+
+			if isDenied(path) {
+				pkg = path
+			}
+		}
+
+		return false
+	})
+
+	if pkg == "" {
+		return nil
+	}
+
+	if *denylist == "warn" {
+		data.warn("function %s uses package %s which gives scripts access to the system, allow it with -allow-denied", decl.Name.Name, pkg)
+
+		return nil
+	}
+
+	return fmt.Errorf("function %s uses package %s which gives scripts access to the system, exclude it with -exclude or allow it with -allow-denied", decl.Name.Name, pkg)
+}
+
+func checkInternal(name string, path string) (string, error) {
+	n, ok := internalRoot(name)
+	if !ok {
+		return *output, nil
+	}
+
+	if !*allowInternal {
+		return "", fmt.Errorf("internal package %s can only be bridged with -allow-internal", name)
+	}
+
+	root := path
+	for i := 0; i < n; i++ {
+		root = filepath.Dir(root)
+	}
+
+	if *output == "" {
+		return root, nil
+	}
+
+	if *output == stdout {
+		return stdout, nil
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if common.Error(err) {
+		return "", err
+	}
+
+	absOutput, err := filepath.Abs(*output)
+	if common.Error(err) {
+		return "", err
+	}
+
+	if _, ok := relPath(absRoot, absOutput); !ok {
+		return "", fmt.Errorf("internal package %s can only be imported from within %s, not from %s", name, absRoot, absOutput)
+	}
+
+	return *output, nil
+}
+
+func getPackageName(name string) string {
+	s := name
+	s = strings.ToLower(strings.ReplaceAll(s, "/", "_"))
+	s = strings.ToLower(strings.ReplaceAll(s, ".", "_"))
+
+	return *prefix + s
+}
+
+func generate(name string) (*Data, error) {
+	spec := strings.ReplaceAll(name, "\\", "/")
+
+	// the version of pkg@version only selects the directory
+	name, _ = splitVersion(spec)
+
+	err := checkDenied(name)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	pathVersion, path, err := findPackagePath(spec)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	fi, err := os.Stat(pathVersion)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", pathVersion)
+	}
+
+	watchMu.Lock()
+	watchedDirs[pathVersion] = true
+	watchMu.Unlock()
+
+	outputDir, err := checkInternal(name, pathVersion)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	ctx := buildContext()
+
+	filenames, err := listDir(&ctx, pathVersion)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	version := moduleVersion(pathVersion)
+	if version == "" {
+		version = vendorVersion(name)
+	}
+
+	key := inputKey(name, version, filenames)
+
+	if data := loadCache(key); data != nil {
+		data.replayDiagnostics()
+
+		return data.writeFiles(key, version, outputDir)
+	}
+
+	fset, files, err := parseFiles(filenames)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	warnings := []string{}
+
+	info, err := typecheck(fset, files, name, pathVersion)
+	if err != nil {
+		// identifiers not resolved by the type check are matched by the suffix of their import paths
+		warnings = append(warnings, fmt.Sprintf("type checking %s failed, qualified identifiers may resolve to the wrong packages: %v", name, err))
+		warn("%s", warnings[0])
+	}
+
+	data, err := analyzeFiles(fset, files, info, name, filepath.Base(path))
+	if common.Error(err) {
+		return nil, err
+	}
+
+	data.warnings = append(warnings, data.warnings...)
+
+	saveCache(key, data)
+
+	return data.writeFiles(key, version, outputDir)
+}
+
+// listDir returns the Go files of the package directory dir matching the build context ctx
+func listDir(ctx *build.Context, dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	filenames := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if common.Error(err) {
+			return nil, err
+		}
+
+		if ok, err := ctx.MatchFile(dir, info.Name()); filter(info) && err == nil && ok {
+			filenames = append(filenames, filepath.Join(dir, info.Name()))
+		}
+	}
+
+	return filenames, nil
+}
+
+// parseFiles parses the Go files in parallel
+func parseFiles(filenames []string) (*token.FileSet, map[string]*ast.File, error) {
+	fset := token.NewFileSet()
+	parsed := make([]*ast.File, len(filenames))
+
+	err := parallel(len(filenames), func(i int) error {
+		file, err := parser.ParseFile(fset, filenames[i], nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+
+		parsed[i] = file
+
+		return nil
+	})
+	if common.Error(err) {
+		return nil, nil, err
+	}
+
+	files := make(map[string]*ast.File)
+	for i, filename := range filenames {
+		files[filename] = parsed[i]
+	}
+
+	return fset, files, nil
+}
+
+func moduleVersion(path string) string {
+	_, version, ok := strings.Cut(filepath.ToSlash(path), "@")
+	if !ok {
+		return ""
+	}
+
+	version, _, _ = strings.Cut(version, "/")
+
+	return version
+}
+
+// Package is a package parsed and type checked by the caller, like the Fset, CompiledGoFiles and
+// Syntax, TypesInfo, PkgPath, Name and Module.Version of a package loaded by go/packages
+type Package struct {
+	Fset *token.FileSet
+	// Files are the parsed files by their filenames
+	Files   map[string]*ast.File
+	Info    *types.Info
+	Path    string
+	Name    string
+	Version string
+}
+
+// GeneratePackage generates the bridge of pkg into outputDir, so callers which loaded the package by
+// other means skip resolving, parsing and type checking it. The bridge is configured by the command
+// line flags like by the goja_go command.
+func GeneratePackage(pkg Package, outputDir string) (*Data, error) {
+	if pkg.Fset == nil || len(pkg.Files) == 0 || pkg.Info == nil {
+		return nil, fmt.Errorf("package %s is passed without its files or type information", pkg.Path)
+	}
+
+	data, err := analyzeFiles(pkg.Fset, pkg.Files, pkg.Info, pkg.Path, pkg.Name)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	return data.writeFiles(inputKey(pkg.Path, pkg.Version, sortedKeys(pkg.Files)), pkg.Version, outputDir)
+}
+
+// analyzeFiles scans the parsed files of the package with the import path pkgPath and the package
+// name inputPkg for the symbols to bridge, resolving their identifiers by the type information info
+func analyzeFiles(fset *token.FileSet, files map[string]*ast.File, info *types.Info, pkgPath string, inputPkg string) (*Data, error) {
+	outputPkg := getPackageName(pkgPath)
+	ctx := buildContext()
+
+	data := &Data{
+		pkgPath:      pkgPath,
+		InputPkg:     inputPkg,
+		OutputPkg:    outputPkg,
+		StructName:   upper1st(outputPkg),
+		JsStructName: lower1st(outputPkg),
+		ImportPaths:  []string{pkgPath},
+		Imports:      nil,
+		Funcs:        nil,
+		Helpers:      make(map[string]bool),
+		fset:         fset,
+		buildCtx:     &ctx,
+		types:        make(map[string]*ast.TypeSpec),
+		methods:      make(map[string][]*ast.FuncDecl),
+	}
+
+	// the package is imported under an alias if named like a package the generated code uses
+	if slices.Contains(generatedPackageNames(), inputPkg) {
+		data.InputPkg = inputAliasPrefix + inputPkg
+	}
+
+	data.addImportPath(*gojaImport)
+	data.addImportPath("errors")
+	data.Stub = *stub
+	data.Split = *split
+	data.Interrupt = *interrupt
+	data.applyProfile()
+
+	if *requirePrefix != "" {
+		data.Require = *requirePrefix + pkgPath
+		data.addImportPath(requireImport)
+	}
+
+	if *metrics {
+		data.MetricsHook = data.metricsHookType()
+	}
+
+	if *sharedRuntime && *gojaImport != runtimeGojaImport {
+		return nil, fmt.Errorf("-shared-runtime requires the goja import %s", runtimeGojaImport)
+	}
+
+	if *duration != "" && *duration != "ms" && *duration != "string" && *duration != "iso" {
+		return nil, fmt.Errorf("invalid duration representation: %s", *duration)
+	}
+
+	if *arrayBuffer != "" && *arrayBuffer != "copy" && *arrayBuffer != "zero-copy" {
+		return nil, fmt.Errorf("invalid array buffer representation: %s", *arrayBuffer)
+	}
+
+	if *arrayBuffer != "" && *bytesAsHex {
+		return nil, fmt.Errorf("-array-buffer cannot be combined with -bytes-as-hex")
+	}
+
+	if *verify != "" && *verify != "report" && *verify != "exclude" {
+		return nil, fmt.Errorf("invalid verification mode: %s", *verify)
+	}
+
+	if *output == stdout && (*dts || *verify != "" || *index != "" || *recursive || *split) {
+		return nil, fmt.Errorf("-o %s cannot be combined with -dts, -verify, -index, -recursive or -split", stdout)
+	}
+
+	if *split && *verify != "" {
+		return nil, fmt.Errorf("-split cannot be combined with -verify")
+	}
+
+	if *output == stdout && *report != "" && *reportFile == "" {
+		return nil, fmt.Errorf("-o %s requires -report-file with -report", stdout)
+	}
+
+	if *dryRun && *verify != "" {
+		return nil, fmt.Errorf("-dry-run cannot be combined with -verify")
+	}
+
+	if *errorMode != "throw" && *errorMode != "tuple" {
+		return nil, fmt.Errorf("invalid error representation: %s", *errorMode)
+	}
+
+	if *mapReturn != "" && *mapReturn != "object" && *mapReturn != "map" {
+		return nil, fmt.Errorf("invalid map return representation: %s", *mapReturn)
+	}
+
+	if *int64Mode != "number" && *int64Mode != "bigint" && *int64Mode != "string" {
+		return nil, fmt.Errorf("invalid int64 representation: %s", *int64Mode)
+	}
+
+	if *collisions != "error" && *collisions != "suffix" {
+		return nil, fmt.Errorf("invalid collision rule: %s", *collisions)
+	}
+
+	if *naming != "camelCase" && *naming != "snake_case" && *naming != "keep-go-name" {
+		return nil, fmt.Errorf("invalid naming strategy: %s", *naming)
+	}
+
+	if *multiReturn != "array" && *multiReturn != "object" {
+		return nil, fmt.Errorf("invalid multiple results representation: %s", *multiReturn)
+	}
+
+	instances, err := parseInstances(*instantiate)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	data.instances = instances
+
+	if *stub && *index != "" {
+		return nil, fmt.Errorf("an index cannot register stub bridges")
+	}
+
+	data.info = info
+	data.qualifyImports(files)
+
+	err = data.scan(files)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	data.finalize()
+
+	return data, nil
+}
+
+// writeFiles renders the analyzed package by the template and writes the generated files into
+// outputDir, with version being the module version of the package (empty if unversioned)
+func (data *Data) writeFiles(key string, version string, outputDir string) (*Data, error) {
+	pkgPath := data.pkgPath
+	outputPkg := data.OutputPkg
+
+	var err error
+
+	filename := strings.ToLower(outputPkg)
+	if *hashedNames {
+		filename += "_" + data.inputHash(pkgPath, version)
+	}
+
+	if outputDir == stdout {
+		data.Filename = filename + ".go"
+	} else {
+		data.Filename, err = filepath.Abs(filepath.Join(outputDir, outputPkg, filename+".go"))
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	if subcommand == "list" {
+		sb := strings.Builder{}
+		printList(&sb, data)
+
+		// packages are generated in parallel, so the list is printed at once
+		fmt.Print(sb.String())
+
+		return data, nil
+	}
+
+	if outputDir != stdout {
+		// an up to date bridge may have been joined by files of another package
+		err = checkPackage(filepath.Dir(data.Filename), data.OutputPkg)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	stamp := inputStamp(key)
+	// the output of a hook is not covered by the stamp
+	if outputDir != stdout && *hook == "" && subcommand != "check" && data.upToDate(stamp) {
+		common.Info("%s is up to date", data.Filename)
+
+		return data, nil
+	}
+
+	tmpl, err := loadTemplate()
+	if common.Error(err) {
+		return nil, err
+	}
+
+	if data.Profile != "" {
+		err = parseProfile(tmpl, data.Profile)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	tmpl.Funcs(template.FuncMap{"tsType": data.tsTypeName})
+
+	err = data.runHook()
+	if common.Error(err) {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	err = tmpl.Execute(&buffer, data)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	ba, err := addHeader(buffer.Bytes())
+	if common.Error(err) {
+		return nil, err
+	}
+
+	ba, err = formatSource(data.Filename, addStamp(ba, stamp))
+	if common.Error(err) {
+		return nil, err
+	}
+
+	if outputDir == stdout {
+		if *dryRun {
+			printPlan(os.Stdout, data)
+
+			return data, nil
+		}
+
+		_, err = os.Stdout.Write(ba)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		return data, nil
+	}
+
+	var splits map[string][]byte
+
+	// the imports of the signatures may only be used by the helpers of the runtime package
+	if data.Split || data.Runtime != 0 {
+		ba, err = data.pruneImports(data.Filename, ba)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	if data.Split {
+		splits, err = data.splitFiles(tmpl, stamp)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	if *dryRun {
+		printPlan(os.Stdout, data)
+	}
+
+	err = removeStale(data.Filename, strings.ToLower(outputPkg))
+	if common.Error(err) {
+		return nil, err
+	}
+
+	err = writeFile(data.Filename, ba)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	err = data.removeStaleSplits(splits)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	filenames := []string{}
+	for filename := range splits {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		err = writeFile(filename, splits[filename])
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	if *dts {
+		buffer.Reset()
+
+		err = tmpl.ExecuteTemplate(&buffer, "dts", data)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		err = writeFile(data.dtsFilename(), addStamp(buffer.Bytes(), stamp))
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	if *tests {
+		buffer.Reset()
+
+		err = tmpl.ExecuteTemplate(&buffer, "test", data)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		ba, err = formatSource(data.testFilename(), addStamp(buffer.Bytes(), stamp))
+		if common.Error(err) {
+			return nil, err
+		}
+
+		err = writeFile(data.testFilename(), ba)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// removeStale removes files of the generated package with a different name from an earlier
+// run with or without -hashed-names, which would otherwise redeclare the bridge
+func removeStale(filename string, name string) error {
+	if *dryRun {
+		return nil
+	}
+
+	stale, err := filepath.Glob(filepath.Join(filepath.Dir(filename), name+"_"+strings.Repeat("[0-9a-f]", 8)+".go"))
+	if common.Error(err) {
+		return err
+	}
+
+	for _, path := range append(stale, filepath.Join(filepath.Dir(filename), name+".go")) {
+		if path == filename {
+			continue
+		}
+
+		if subcommand == "check" {
+			checkRemoved(path)
+
+			continue
+		}
+
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inputHash returns a short hash of the inputs the generated file depends on, which is stable
+// as long as the package version and the bridged functions are unchanged
+func (data *Data) inputHash(pkgPath string, version string) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%s@%s\n", pkgPath, version)
+
+	for _, f := range data.Funcs {
+		fmt.Fprintf(h, "%s%s %s\n", f.Name, f.Params, f.Results)
+	}
+
+	for _, t := range data.Types {
+		for _, f := range t.Methods {
+			fmt.Fprintf(h, "%s.%s%s %s\n", t.Name, f.Name, f.Params, f.Results)
+		}
+
+		for _, p := range t.Properties {
+			fmt.Fprintf(h, "%s.%s %s %s\n", t.Name, p.Name, p.Get.Results, p.Set.Params)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// templateFuncs are the functions available to templates besides the builtin ones. Functions taking
+// a string last are usable at the end of a pipeline, like {{ .Name | hasPrefix "New" }}.
+var templateFuncs = template.FuncMap{
+	// jsdoc escapes a line of a doc comment for a JSDoc block
+	"jsdoc": func(s string) string {
+		return strings.ReplaceAll(s, "*/", "*\\/")
+	},
+	"lower1st":  lower1st,
+	"upper1st":  upper1st,
+	"camelCase": camelCase,
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	"gojaImport": func() string {
+		return *gojaImport
+	},
+	"hasPrefix": func(prefix string, s string) bool {
+		return strings.HasPrefix(s, prefix)
+	},
+	// tsType maps a Go type to its TS type, bound to the package by writeFiles
+	"tsType": (&Data{}).tsTypeName,
+	// default returns value unless it is empty, then def
+	"default": func(def any, value any) any {
+		if value == nil || reflect.ValueOf(value).IsZero() {
+			return def
+		}
+
+		return value
+	},
+}
+
+// templateFiles returns the file of the -t template or the *.tmpl files of its directory
+func templateFiles() []string {
+	if *tmpl == "" {
+		return nil
+	}
+
+	info, err := os.Stat(*tmpl)
+	if err != nil || !info.IsDir() {
+		return []string{*tmpl}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(*tmpl, "*.tmpl"))
+
+	return files
+}
+
+// loadTemplate parses the -t template. A directory holds templates replacing the blocks of the
+// built-in template named like them, e.g. function.tmpl replaces the "function" block.
+func loadTemplate() (*template.Template, error) {
+	if *tmpl == "" {
+		return template.New("goja_go.tmpl").Funcs(templateFuncs).Parse(defaultTmpl)
+	}
+
+	if info, err := os.Stat(*tmpl); err != nil || !info.IsDir() {
+		t, err := template.New(filepath.Base(*tmpl)).Funcs(templateFuncs).ParseFiles(*tmpl)
+		if err != nil {
+			return nil, templateError(*tmpl, err)
+		}
+
+		return t, nil
+	}
+
+	t, err := template.New("goja_go.tmpl").Funcs(templateFuncs).Parse(defaultTmpl)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	for _, filename := range templateFiles() {
+		ba, err := os.ReadFile(filename)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		_, err = t.New(strings.TrimSuffix(filepath.Base(filename), ".tmpl")).Parse(string(ba))
+		if err != nil {
+			return nil, templateError(filename, err)
+		}
+	}
+
+	return t, nil
+}
+
+func templateError(filename string, err error) error {
+	path, _ := filepath.Abs(filename)
+	if path == "" {
+		path = filename
+	}
+
+	match := regexp.MustCompile(`:(\d+):`).FindStringSubmatch(err.Error())
+	if match == nil {
+		return fmt.Errorf("invalid template file %s: %w", path, err)
+	}
+
+	line, _ := strconv.Atoi(match[1])
+
+	ba, _ := os.ReadFile(filename)
+	lines := strings.Split(string(ba), "\n")
+	if line < 1 || line > len(lines) || strings.TrimSpace(lines[line-1]) == "" {
+		return fmt.Errorf("invalid template file %s at line %d: %w", path, line, err)
+	}
+
+	return fmt.Errorf("invalid template file %s at line %d near %q: %w", path, line, strings.TrimSpace(lines[line-1]), err)
+}
+
+func importPath(dir string) (string, error) {
+	if *gomodFile == "" {
+		srcs, err := gopathSrcs()
+		if common.Error(err) {
+			return "", err
+		}
+
+		for _, src := range srcs {
+			if rel, ok := relPath(src, dir); ok {
+				return filepath.ToSlash(rel), nil
+			}
+		}
+
+		return "", fmt.Errorf("directory %s is not part of GOPATH", dir)
+	}
+
+	gomod, err := readGomod()
+	if common.Error(err) {
+		return "", err
+	}
+
+	root, err := filepath.Abs(filepath.Dir(*gomodFile))
+	if common.Error(err) {
+		return "", err
+	}
+
+	rel, ok := relPath(root, dir)
+	if !ok {
+		return "", fmt.Errorf("directory %s is not part of the module %s", dir, gomod.Module.Mod.Path)
+	}
+
+	return strings.TrimSuffix(gomod.Module.Mod.Path+"/"+filepath.ToSlash(rel), "/."), nil
+}
+
+func writeIndex(datas []*Data, namespace string) error {
+	idx := Index{
+		OutputPkg: *prefix + "index",
+		Namespace: namespace,
+		Imports:   []string{*gojaImport},
+		Packages:  datas,
+		Interrupt: *interrupt,
+	}
+
+	if *metrics {
+		idx.MetricsHook = "interface {\nBefore(name string)\nAfter(name string, duration time.Duration)\n}"
+		idx.Imports = append(idx.Imports, "time")
+
+		if *metricsHook != "" {
+			path, name := splitQualified(*metricsHook)
+
+			idx.MetricsHook = filepath.Base(path) + "." + name
+			idx.Imports = []string{*gojaImport, path}
+		}
+	}
+
+	if *requirePrefix != "" {
+		idx.Require = true
+		idx.Imports = append(idx.Imports, requireImport)
+	}
+
+	for _, data := range datas {
+		path, err := importPath(filepath.Dir(data.Filename))
+		if common.Error(err) {
+			return err
+		}
+
+		idx.Imports = append(idx.Imports, path)
+	}
+
+	sort.Strings(idx.Imports)
+
+	tmpl, err := loadTemplate()
+	if common.Error(err) {
+		return err
+	}
+
+	var buffer bytes.Buffer
+
+	err = tmpl.ExecuteTemplate(&buffer, "index", idx)
+	if common.Error(err) {
+		return err
+	}
+
+	ba, err := addHeader(buffer.Bytes())
+	if common.Error(err) {
+		return err
+	}
+
+	filename, err := filepath.Abs(filepath.Join(*output, "index.go"))
+	if common.Error(err) {
+		return err
+	}
+
+	ba, err = formatSource(filename, ba)
+	if common.Error(err) {
+		return err
+	}
+
+	err = checkPackage(filepath.Dir(filename), idx.OutputPkg)
+	if common.Error(err) {
+		return err
+	}
+
+	err = writeFile(filename, ba)
+	if common.Error(err) {
+		return err
+	}
+
+	if !*dts {
+		return nil
+	}
+
+	for _, data := range datas {
+		rel, err := filepath.Rel(filepath.Dir(filename), data.dtsFilename())
+		if common.Error(err) {
+			return err
+		}
+
+		idx.Declarations = append(idx.Declarations, filepath.ToSlash(rel))
+	}
+
+	buffer.Reset()
+
+	err = tmpl.ExecuteTemplate(&buffer, "index.d.ts", idx)
+	if common.Error(err) {
+		return err
+	}
+
+	return writeFile(filepath.Join(filepath.Dir(filename), "index.d.ts"), buffer.Bytes())
+}
+
+// formatSource formats the generated code of filename like gofmt and reports the line of
+// the first syntax error otherwise, which is most likely caused by a custom template
+func formatSource(filename string, ba []byte) ([]byte, error) {
+	src, err := format.Source(ba)
+	if err == nil {
+		return src, nil
+	}
+
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return nil, fmt.Errorf("invalid generated code %s: %w", filename, err)
+	}
+
+	line := list[0].Pos.Line
+
+	lines := strings.Split(string(ba), "\n")
+	if line < 1 || line > len(lines) || strings.TrimSpace(lines[line-1]) == "" {
+		return nil, fmt.Errorf("invalid generated code %s at line %d: %s", filename, line, list[0].Msg)
+	}
+
+	return nil, fmt.Errorf("invalid generated code %s at line %d near %q: %s", filename, line, strings.TrimSpace(lines[line-1]), list[0].Msg)
+}
+
+func addHeader(ba []byte) ([]byte, error) {
+	if *headerFile == "" {
+		return ba, nil
+	}
+
+	header, err := os.ReadFile(*headerFile)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(header), "\r\n", "\n"), "\n")
+	inBlock := false
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(line, "*/")
+		case line == "", strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "/*"):
+			inBlock = !strings.Contains(line[2:], "*/")
+		default:
+			return nil, fmt.Errorf("invalid header file %s at line %d: only comments and build constraints are allowed: %s", *headerFile, i+1, line)
+		}
+	}
+
+	if inBlock {
+		return nil, fmt.Errorf("invalid header file %s: unterminated block comment", *headerFile)
+	}
+
+	s := strings.TrimSpace(strings.Join(lines, "\n"))
+	if s == "" {
+		return ba, nil
+	}
+
+	return append([]byte(s+"\n\n"), ba...), nil
+}
+
+func splitQualified(s string) (string, string) {
+	p := strings.LastIndex(s, ".")
+	if p == -1 || p < strings.LastIndex(s, "/") {
+		return "", s
+	}
+
+	return s[:p], s[p+1:]
+}
+
+func (data *Data) metricsHookType() string {
+	data.addImportPath("time")
+
+	path, name := splitQualified(*metricsHook)
+	if *metricsHook == "" {
+		return "MetricsHook"
+	}
+
+	if path == "" {
+		return name
+	}
+
+	data.addImportPath(path)
+
+	return filepath.Base(path) + "." + name
+}
+
+func checkPackage(dir string, pkg string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if common.Error(err) {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+
+		file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.PackageClauseOnly)
+		if common.Error(err) {
+			return err
+		}
+
+		name := file.Name.Name
+		if name == pkg || (strings.HasSuffix(entry.Name(), "_test.go") && name == pkg+"_test") {
+			continue
+		}
+
+		return fmt.Errorf("cannot generate package %s into %s: %s declares package %s", pkg, dir, entry.Name(), name)
+	}
+
+	return nil
+}
+
+func writeFile(filename string, ba []byte) error {
+	if subcommand == "check" {
+		return checkFile(filename, ba)
+	}
+
+	if *dryRun {
+		dryRunFile(filename)
+
+		return nil
+	}
+
+	if *output == stdout {
+		fmt.Fprintf(os.Stderr, "%s\n", filename)
+	} else {
+		fmt.Printf("%s\n", filename)
+	}
+
+	err := os.MkdirAll(filepath.Dir(filename), common.DefaultDirMode)
+	if common.Error(err) {
+		return err
+	}
+
+	err = os.WriteFile(filename, ba, common.DefaultFileMode)
+	if common.Error(err) {
+		return err
+	}
+
+	trackArtifact(filename)
+
+	return nil
+}
+
+// compileFilters compiles the -include and -exclude expressions together with those of
+// the package of the config file being generated
+func compileFilters() error {
+	compile := func(flagName string, patterns []string) ([]*regexp.Regexp, error) {
+		res := []*regexp.Regexp{}
+		for _, pattern := range patterns {
+			if pattern == "" {
+				continue
+			}
+
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -%s expression: %w", flagName, err)
+			}
+
+			res = append(res, re)
+		}
+
+		return res, nil
+	}
+
+	var err error
+
+	includes, err = compile("include", append([]string{*include}, configIncludes...))
+	if common.Error(err) {
+		return err
+	}
+
+	excludes, err = compile("exclude", append([]string{*exclude}, configExcludes...))
+	if common.Error(err) {
+		return err
+	}
+
+	asyncs, err = compile("async", []string{*async})
+	if common.Error(err) {
+		return err
+	}
+
+	return nil
+}
+
+// filtered returns why the symbol name is filtered out by -include and -exclude, if it is
+func filtered(name string) string {
+	matches := func(res []*regexp.Regexp) bool {
+		return slices.ContainsFunc(res, func(re *regexp.Regexp) bool {
+			return re.MatchString(name)
+		})
+	}
+
+	if len(includes) > 0 && !matches(includes) {
+		return "filtered by -include"
+	}
+
+	if matches(excludes) {
+		return "filtered by -exclude"
+	}
+
+	return ""
+}
+
+func readManifest() error {
+	if *manifest == "" {
+		return nil
+	}
+
+	ba, err := os.ReadFile(*manifest)
+	if common.Error(err) {
+		return err
+	}
+
+	manifestSymbols = make(map[string]bool)
+
+	for _, line := range strings.Split(string(ba), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		manifestSymbols[line] = true
+	}
+
+	return nil
+}
+
+func saveManifest(datas []*Data) error {
+	if *writeManifest == "" {
+		return nil
+	}
+
+	s := ""
+
+	for _, data := range datas {
+		symbols := slices.Clone(data.symbols)
+		sort.Strings(symbols)
+
+		if s != "" {
+			s += "\n"
+		}
+
+		s += fmt.Sprintf("# symbols of %s allowed to be bridged\n", data.pkgPath)
+		for _, symbol := range symbols {
+			s += symbol + "\n"
+		}
+	}
+
+	return writeFile(*writeManifest, []byte(s))
+}
+
+func run() (err error) {
+	if *diagFormat != "text" && *diagFormat != "json" {
+		return fmt.Errorf("invalid diagnostics format: %s", *diagFormat)
+	}
+
+	if jsonDiagnostics() {
+		silenceLogs()
+
+		defer func() {
+			if err != nil {
+				diagnoseError(err)
+			}
+		}()
+	}
+
+	if *exportTmpl != "" {
+		err := os.WriteFile(*exportTmpl, []byte(defaultTmpl), common.DefaultFileMode)
+		if common.Error(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	if (*output == stdout || subcommand != "") && !jsonDiagnostics() {
+		// keep stdout clean for the generated source or the list
+		common.LogInfo.SetOutput(os.Stderr)
+		common.LogWarn.SetOutput(os.Stderr)
+	}
+
+	if subcommand == "clean" {
+		return cleanArtifacts()
+	}
+
+	if subcommand == "check" && (*output == stdout || *dryRun || *watch) {
+		return fmt.Errorf("check cannot be combined with -o %s, -dry-run or -watch", stdout)
+	}
+
+	err = runAll()
+	if subcommand == "check" {
+		if err == nil {
+			err = reportCheck()
+		}
+
+		if common.Error(err) {
+			if jsonDiagnostics() {
+				diagnoseError(err)
+			}
+
+			// errors returned by run do not change the exit code, but a CI gate depends on it
+			common.Exit(1)
+		}
+
+		return nil
+	}
+
+	if !*watch {
+		return err
+	}
+
+	return watchSources(runAll)
+}
+
+func runAll() error {
+	verifyExcluded = make(map[string]bool)
+
+	err := readManifest()
+	if common.Error(err) {
+		return err
+	}
+
+	if *configFile != "" {
+		return runConfig()
+	}
+
+	return runPackages()
+}
+
+func runPackages() error {
+	err := compileFilters()
+	if common.Error(err) {
+		return err
+	}
+
+	names := []string{}
+	expanded := make(map[string]bool)
+	namespace := *index
+
+	for _, name := range strings.Split(*pkgName, ",") {
+		name = strings.TrimSpace(name)
+
+		base, _ := splitVersion(name)
+		if !strings.HasSuffix(base, patternSuffix) {
+			names = append(names, name)
+
+			continue
+		}
+
+		subs, err := expandPattern(name)
+		if common.Error(err) {
+			return err
+		}
+
+		for _, sub := range subs {
+			if !slices.Contains(names, sub) {
+				expanded[sub] = true
+				names = append(names, sub)
+			}
+		}
+
+		// the packages of a pattern are registered together
+		if namespace == "" && !*stub {
+			namespace = camelCase(path.Base(strings.TrimSuffix(base, patternSuffix)))
+		}
+	}
+
+	if len(names) > 1 && *reportFile != "" {
+		return fmt.Errorf("a report file cannot be shared by multiple packages")
+	}
+
+	if len(names) > 1 && *output == stdout {
+		return fmt.Errorf("multiple packages cannot be written to stdout")
+	}
+
+	datas := []*Data{}
+	deps := make(map[string]bool)
+
+	// generate in waves of the requested packages and then of the dependencies they add
+	for start := 0; start < len(names); {
+		wave := names[start:]
+		start = len(names)
+
+		results := make([]*Data, len(wave))
+		errs := make([]error, len(wave))
+
+		_ = parallel(len(wave), func(i int) error {
+			if name := strings.TrimSpace(wave[i]); name != "" {
+				results[i], errs[i] = generate(name)
+			}
+
+			return nil
+		})
+
+		for i, name := range wave {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			data, err := results[i], errs[i]
+			if err == nil && *verify != "" && subcommand == "" {
+				data, err = verifyPackage(name, data)
+			}
+
+			if deps[name] && err != nil {
+				warn("skipped dependency %s: %v", name, err)
+
+				continue
+			}
+
+			if expanded[name] && err != nil {
+				warn("skipped package %s: %v", name, err)
+
+				continue
+			}
+
+			if common.Error(err) {
+				return err
+			}
+
+			if *recursive {
+				for _, dep := range data.deps {
+					if !slices.Contains(names, dep) {
+						deps[dep] = true
+						names = append(names, dep)
+					}
+				}
+			}
+
+			datas = append(datas, data)
+
+			if subcommand == "list" {
+				continue
+			}
+
+			err = saveReport(data)
+			if common.Error(err) {
+				return err
+			}
+		}
+	}
+
+	if subcommand == "list" {
+		return nil
+	}
+
+	err = saveManifest(datas)
+	if common.Error(err) {
+		return err
+	}
+
+	err = saveIR(datas)
+	if common.Error(err) {
+		return err
+	}
+
+	if namespace != "" {
+		err := writeIndex(datas, namespace)
+		if common.Error(err) {
+			return err
+		}
+	}
+
+	if subcommand == "check" {
+		return nil
+	}
+
+	return saveArtifacts()
+}
+
+// Main runs the goja_go command line with the resources embedded by the main package, which
+// common.Init reads the module of the application from
+func Main(resources *embed.FS) {
+	common.Init("", "", "", "", "create GOJA JS bridges to GO modules", "", "", "", resources, nil, nil, run, 0)
+
+	// common.Run rejects arguments after the flags, so the subcommand precedes them
+	if len(os.Args) > 1 && slices.Contains([]string{"list", "check", "clean"}, os.Args[1]) {
+		subcommand = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	mandatory := []string{"n|export-template|config"}
+	if subcommand == "clean" {
+		mandatory = nil
+	}
+
+	common.Run(mandatory)
+}
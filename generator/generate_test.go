@@ -0,0 +1,1040 @@
+package generator
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// testdataPkg is the import path of the packages in testdata
+const testdataPkg = "github.com/mpetavy/goja_go/generator/testdata/"
+
+// resetTestFlags resets all flags and the state derived from them
+func resetTestFlags() {
+	flag.VisitAll(func(fl *flag.Flag) {
+		if !strings.HasPrefix(fl.Name, "test.") {
+			_ = fl.Value.Set(fl.DefValue)
+		}
+	})
+
+	includes, excludes, asyncs = nil, nil, nil
+	manifestSymbols = nil
+	gomod, vendorPackages = nil, nil
+}
+
+// parseTestFlags parses the command line flags args for the test after resetting those of a
+// previous parse and resets them afterwards
+func parseTestFlags(t *testing.T, args ...string) {
+	t.Helper()
+
+	resetTestFlags()
+	t.Cleanup(resetTestFlags)
+
+	err := flag.CommandLine.Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runTestdata generates the bridge of the package testdata/name like the goja_go command with
+// the flags args into a temporary directory unless args set -o
+func runTestdata(t *testing.T, name string, args ...string) error {
+	t.Helper()
+
+	parseTestFlags(t, append([]string{"-g", filepath.Join("..", "go.mod"), "-n", testdataPkg + name, "-o", t.TempDir(), "-cache=false"}, args...)...)
+
+	return runAll()
+}
+
+// generateTestdata generates the bridge of the package testdata/name by runTestdata and returns
+// the generated source
+func generateTestdata(t *testing.T, name string, args ...string) string {
+	t.Helper()
+
+	err := runTestdata(t, name, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return readBridge(t, name)
+}
+
+// bridgeFilename returns the filename of the bridge of the package testdata/name generated into -o
+func bridgeFilename(name string) string {
+	outputPkg := getPackageName(testdataPkg + name)
+
+	return filepath.Join(*output, outputPkg, outputPkg+".go")
+}
+
+// readBridge returns the source of the bridge of the package testdata/name generated into -o
+func readBridge(t *testing.T, name string) string {
+	t.Helper()
+
+	ba, err := os.ReadFile(bridgeFilename(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(ba)
+}
+
+// buildBridges generates the bridges of the comma separated packages pkgs with the flags args
+// into a temporary package of the runtime module, which requires goja, and vets them in the go
+// version of the toolchain, whose standard library may be newer than the module
+func buildBridges(t *testing.T, pkgs string, args ...string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping the build of the bridges with goja in short mode")
+	}
+
+	dir, err := os.MkdirTemp(filepath.Join("..", "runtime"), "bridgetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	version := regexp.MustCompile(`^go1\.\d+`).FindString(runtime.Version())
+	if version == "" {
+		t.Skipf("skipping the build of the bridges with the development toolchain %s", runtime.Version())
+	}
+
+	header := filepath.Join(t.TempDir(), "header.txt")
+
+	err = os.WriteFile(header, []byte("//go:build "+version+"\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parseTestFlags(t, append([]string{"-g", filepath.Join("..", "runtime", "go.mod"), "-n", pkgs, "-o", dir, "-cache=false", "-denylist=", "-header-file", header}, args...)...)
+
+	err = runAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = dir
+
+	ba, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, ba)
+	}
+}
+
+// bridgeTest is a Go test run with goja in the package of a generated bridge
+type bridgeTest struct {
+	// Args are the flags the bridge is generated with
+	Args []string
+	// Decls are the Go declarations of the test file
+	Decls string
+	// Go are the statements of the test before Script, by default register(newBridge(vm)). The
+	// test declares vm as new runtime, register to set the global "bridge" and run to run a script.
+	// The file declares bridgeStruct as alias of the bridge struct
+	Go string
+	// Script is the JS run after Go, which fails the test by throwing
+	Script string
+}
+
+// bridgeTestSource is the source of a bridgeTest in the package of the bridge with struct name
+const bridgeTestSource = `package %s
+
+import (
+%s	"testing"
+
+	"github.com/dop251/goja"
+)
+
+type bridgeStruct = %[3]s
+
+var newBridge = New%[3]sObject
+%[4]s
+%[7]s
+
+func TestBridge(t *testing.T) {
+	vm := goja.New()
+
+	register := func(obj *goja.Object, err error) {
+		t.Helper()
+
+		if err == nil {
+			err = vm.Set("bridge", obj)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(script string) goja.Value {
+		t.Helper()
+
+		v, err := vm.RunString(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return v
+	}
+
+	_, _ = register, run
+
+	%[5]s
+
+	run(%[6]q)
+}
+`
+
+// runBridge copies the package testdata/name into a temporary package of the runtime module,
+// which requires goja, generates its bridge and runs test by go test in the package of the bridge
+func runBridge(t *testing.T, name string, test bridgeTest) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping the build of the bridge with goja in short mode")
+	}
+
+	dir, err := os.MkdirTemp(filepath.Join("..", "runtime"), "bridgetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	filenames, err := filepath.Glob(filepath.Join("testdata", name, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.MkdirAll(filepath.Join(dir, name), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range filenames {
+		ba, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name, filepath.Base(filename)), ba, os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgPath := "github.com/mpetavy/goja_go/runtime/" + filepath.Base(dir) + "/" + name
+
+	parseTestFlags(t, append([]string{"-g", filepath.Join("..", "runtime", "go.mod"), "-n", pkgPath, "-o", dir, "-cache=false"}, test.Args...)...)
+
+	err = runAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputPkg := getPackageName(pkgPath)
+	structName := upper1st(outputPkg)
+
+	if test.Go == "" {
+		test.Go = "register(newBridge(vm))"
+	}
+
+	imports := ""
+	for _, pkg := range []string{"errors", "fmt", "strings", "time"} {
+		if strings.Contains(test.Decls+test.Go, pkg+".") {
+			imports += fmt.Sprintf("\t%q\n", pkg)
+		}
+	}
+
+	stubs := ""
+	if strings.Contains(strings.Join(test.Args, " "), "-stub") {
+		stubs = fmt.Sprintf("\ntype bridgeStubs = %sStubs\n", structName)
+	}
+
+	source := fmt.Sprintf(bridgeTestSource, outputPkg, imports, structName, stubs, test.Go, test.Script, test.Decls)
+
+	err = os.WriteFile(filepath.Join(dir, outputPkg, "bridge_test.go"), []byte(source), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "test", "-count=1", ".")
+	cmd.Dir = filepath.Join(dir, outputPkg)
+
+	ba, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, ba)
+	}
+}
+
+// analyzeTestdata scans the files of the package testdata/name without resolving it, so the files
+// do not need to match the build context
+func analyzeTestdata(t *testing.T, name string) (*Data, error) {
+	t.Helper()
+
+	dir := filepath.Join("testdata", name)
+
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset, files, err := parseFiles(filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the files of the same package may redeclare their functions
+	info, _ := typecheck(fset, files, testdataPkg+name, dir)
+
+	return analyzeFiles(fset, files, info, testdataPkg+name, name)
+}
+
+func TestGeneratePackage(t *testing.T) {
+	// the types of the imports are checked from their sources
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax |
+		packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps | packages.NeedModule
+
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: filepath.Join("testdata", "builtins")}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 {
+		t.Fatalf("expected the package builtins, got %v", pkgs)
+	}
+
+	loaded := pkgs[0]
+
+	files := make(map[string]*ast.File)
+	for i, file := range loaded.Syntax {
+		files[loaded.CompiledGoFiles[i]] = file
+	}
+
+	pkg := Package{
+		Fset:  loaded.Fset,
+		Files: files,
+		Info:  loaded.TypesInfo,
+		Path:  loaded.PkgPath,
+		Name:  loaded.Name,
+	}
+
+	if loaded.Module != nil {
+		pkg.Version = loaded.Module.Version
+	}
+
+	data, err := GeneratePackage(pkg, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := os.ReadFile(data.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, string(ba), "package goja_go_github_com_mpetavy_goja_go_generator_testdata_builtins", `"`+testdataPkg+`builtins"`, `obj.Set("len", s.Len)`, "builtins.Wait(")
+}
+
+// assertContains fails the test for each of wants missing in the generated source s
+func assertContains(t *testing.T, s string, wants ...string) {
+	t.Helper()
+
+	for _, want := range wants {
+		if !strings.Contains(s, want) {
+			t.Errorf("generated source misses %q", want)
+		}
+	}
+}
+
+func TestDuplicateFunctionsByBuildContext(t *testing.T) {
+	for _, goos := range []string{"linux", "darwin"} {
+		parseTestFlags(t, "-goos", goos)
+
+		data, err := analyzeTestdata(t, "platform")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if file := filepath.Base(data.Funcs[data.indexFunc("Platform")].File); file != "platform_"+goos+".go" {
+			t.Errorf("-goos %s bridges Platform of %s", goos, file)
+		}
+
+		if len(data.Skipped) != 1 || !strings.HasPrefix(data.Skipped[0].Reason, "superseded") {
+			t.Errorf("-goos %s does not skip the superseded Platform: %v", goos, data.Skipped)
+		}
+	}
+
+	parseTestFlags(t, "-goos", "windows")
+
+	_, err := analyzeTestdata(t, "platform")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous function Platform") {
+		t.Errorf("expected Platform to be ambiguous for windows, got %v", err)
+	}
+}
+
+func TestSupersededPosition(t *testing.T) {
+	parseTestFlags(t, "-goos", "linux")
+
+	data, err := analyzeTestdata(t, "platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the declaration in platform_darwin.go is bridged first and superseded by the one of linux
+	if len(data.Skipped) != 1 || data.Skipped[0].Position != "platform_darwin.go:3:1" {
+		t.Errorf("expected the superseded Platform at platform_darwin.go:3:1, got %v", data.Skipped)
+	}
+}
+
+func TestIsDenied(t *testing.T) {
+	for _, pkg := range []string{"net", "net/http", "net/http/httputil", "net/rpc", "net/smtp", "os", "os/exec", "syscall"} {
+		if !isDenied(pkg) {
+			t.Errorf("%s is not denied", pkg)
+		}
+	}
+
+	for _, pkg := range []string{"net/mail", "net/netip", "net/url", "network", "strings"} {
+		if isDenied(pkg) {
+			t.Errorf("%s is denied", pkg)
+		}
+	}
+
+	parseTestFlags(t, "-allow-denied", "net/http, os")
+
+	if isDenied("net/http") || isDenied("os") || !isDenied("os/exec") {
+		t.Errorf("-allow-denied does not allow exactly net/http and os")
+	}
+}
+
+func TestAllowInternal(t *testing.T) {
+	err := runTestdata(t, "internal/secret")
+	if err == nil || !strings.Contains(err.Error(), "-allow-internal") {
+		t.Errorf("expected the internal package to require -allow-internal, got %v", err)
+	}
+
+	err = runTestdata(t, "internal/secret", "-allow-internal")
+	if err == nil || !strings.Contains(err.Error(), "can only be imported from within") {
+		t.Errorf("expected the internal package to be refused outside testdata, got %v", err)
+	}
+
+	// testdata is the root of the internal package
+	dir, err := os.MkdirTemp("testdata", "internal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	source := generateTestdata(t, "internal/secret", "-allow-internal", "-o", dir)
+
+	assertContains(t, source, `"`+testdataPkg+`internal/secret"`, "secret.Reveal(")
+
+	runBridge(t, "internal/secret", bridgeTest{
+		Args:   []string{"-allow-internal"},
+		Script: `if (bridge.reveal() !== "secret") throw new Error("reveal returns " + bridge.reveal())`,
+	})
+}
+
+func TestRecursiveIndex(t *testing.T) {
+	// the index imports the bridges from within the module
+	dir, err := os.MkdirTemp("testdata", "recursive")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	err = runTestdata(t, "recursive/app", "-recursive", "-index", "app", "-o", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := os.ReadFile(filepath.Join(dir, "index.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := string(ba)
+
+	for _, name := range []string{"recursive/app", "recursive/model"} {
+		outputPkg := getPackageName(testdataPkg + name)
+
+		assertContains(t, index,
+			fmt.Sprintf(`"%s/%s/%s"`, strings.TrimSuffix(testdataPkg, "/"), filepath.Base(dir), outputPkg),
+			fmt.Sprintf("%s.New%sObject(vm)", outputPkg, upper1st(outputPkg)),
+			fmt.Sprintf(`ns.Set("%s", obj)`, lower1st(outputPkg)))
+	}
+
+	assertContains(t, index, `vm.Set("app", ns)`)
+}
+
+func TestHeaderFile(t *testing.T) {
+	header := filepath.Join(t.TempDir(), "header.txt")
+
+	err := os.WriteFile(header, []byte("/*\n * Copyright (c) the authors\n * Licensed under the Apache License 2.0\n */\n\n//go:build linux\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := generateTestdata(t, "builtins", "-header-file", header)
+
+	license := strings.Index(source, "/*\n * Copyright (c) the authors")
+	constraint := strings.Index(source, "\n//go:build linux\n\n")
+	pkg := strings.Index(source, "\npackage ")
+
+	// go/format moves the build constraint in front of the other comments
+	if !strings.HasPrefix(source, stampPrefix) || license == -1 || constraint == -1 || license > pkg || constraint > pkg {
+		t.Errorf("expected the license and the build constraint before the package clause:\n%s", source[:pkg+1])
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, bridgeFilename("builtins"), nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Doc != nil {
+		t.Errorf("the header is the package comment: %s", file.Doc.Text())
+	}
+
+	for goos, want := range map[string]bool{"linux": true, "darwin": false} {
+		ctx := build.Default
+		ctx.GOOS = goos
+
+		match, err := ctx.MatchFile(filepath.Dir(bridgeFilename("builtins")), filepath.Base(bridgeFilename("builtins")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if match != want {
+			t.Errorf("the build constraint matches %s: %v", goos, match)
+		}
+	}
+
+	err = os.WriteFile(header, []byte("// Copyright (c) the authors\npackage builtins\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runTestdata(t, "builtins", "-header-file", header)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected the header file to be invalid at line 2, got %v", err)
+	}
+}
+
+func TestGojaImport(t *testing.T) {
+	const fork = "example.com/fork/goja"
+
+	err := runTestdata(t, "builtins", "-goja-import", fork, "-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := bridgeFilename("builtins")
+
+	for _, filename := range []string{filename, strings.TrimSuffix(filename, ".go") + "_test.go"} {
+		file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		forked := false
+		for _, imp := range file.Imports {
+			switch imp.Path.Value {
+			case `"` + fork + `"`:
+				forked = true
+			case `"github.com/dop251/goja"`:
+				t.Errorf("%s imports the default goja", filepath.Base(filename))
+			}
+		}
+
+		if !forked {
+			t.Errorf("%s does not import %s", filepath.Base(filename), fork)
+		}
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	runBridge(t, "metrics", bridgeTest{
+		Args: []string{"-metrics"},
+		Decls: `type recorder struct {
+	before, after []string
+	durations     []time.Duration
+}
+
+func (r *recorder) Before(name string) {
+	r.before = append(r.before, name)
+}
+
+func (r *recorder) After(name string, duration time.Duration) {
+	r.after = append(r.after, name)
+	r.durations = append(r.durations, duration)
+}`,
+		Go: `hook := &recorder{}
+	register(newBridge(vm, hook))
+
+	t.Cleanup(func() {
+		if want := "Add Wait"; strings.Join(hook.before, " ") != want || strings.Join(hook.after, " ") != want {
+			t.Errorf("expected the hook to observe %s, got %v and %v", want, hook.before, hook.after)
+		}
+
+		for i, duration := range hook.durations {
+			if duration <= 0 {
+				t.Errorf("%s takes %v", hook.after[i], duration)
+			}
+		}
+	})`,
+		Script: `
+			if (bridge.add(1, 2) !== 3) {
+				throw new Error("add returns " + bridge.add(1, 2));
+			}
+
+			bridge.wait(1);`,
+	})
+
+	// the hook is optional
+	runBridge(t, "metrics", bridgeTest{
+		Args:   []string{"-metrics"},
+		Go:     "register(newBridge(vm, nil))",
+		Script: `bridge.wait(0)`,
+	})
+}
+
+func TestConflictingPackage(t *testing.T) {
+	dir := t.TempDir()
+	outputPkg := getPackageName(testdataPkg + "builtins")
+
+	err := os.MkdirAll(filepath.Join(dir, outputPkg), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// regenerating and external tests are no conflicts
+	err = os.WriteFile(filepath.Join(dir, outputPkg, "bridge_test.go"), []byte("package "+outputPkg+"_test\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		err = runTestdata(t, "builtins", "-o", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(dir, outputPkg, "other.go"), []byte("package other\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runTestdata(t, "builtins", "-o", dir)
+	if err == nil || !strings.Contains(err.Error(), "other.go declares package other") {
+		t.Errorf("expected other.go to conflict with the bridge, got %v", err)
+	}
+}
+
+func TestGopathMode(t *testing.T) {
+	gopath := t.TempDir()
+
+	filename := filepath.Join(gopath, "src", "example.com", "legacy", "legacy.go")
+
+	err := os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filename, []byte("package legacy\n\nfunc Greet(name string) string { return \"hello \" + name }\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOPATH", gopath)
+
+	resetGoEnv := func() {
+		goEnvMu.Lock()
+		defer goEnvMu.Unlock()
+
+		delete(goEnvs, "GOPATH")
+	}
+
+	resetGoEnv()
+	t.Cleanup(resetGoEnv)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no go.mod is found from the working directory
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	parseTestFlags(t, "-n", "example.com/legacy", "-o", t.TempDir(), "-cache=false")
+
+	err = runAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gomod != nil {
+		t.Errorf("expected GOPATH mode, got the go.mod of %s", gomod.Module.Mod.Path)
+	}
+
+	ba, err := os.ReadFile(filepath.Join(*output, "goja_go_example_com_legacy", "goja_go_example_com_legacy.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, string(ba), `"example.com/legacy"`, "legacy.Greet(")
+}
+
+func TestImportOfNestedTypes(t *testing.T) {
+	source := generateTestdata(t, "nested")
+
+	assertContains(t, source, `"net/url"`)
+
+	// the bridge only builds with the import
+	runBridge(t, "nested", bridgeTest{
+		Script: `
+			if (bridge.f([]) !== 0 || bridge.g([[], []]) !== 2) {
+				throw new Error("f or g count wrong");
+			}`,
+	})
+}
+
+func TestManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.txt")
+
+	err := runTestdata(t, "manifest", "-write-manifest", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# symbols of " + testdataPkg + "manifest allowed to be bridged\nAlpha\nBeta\nDelta\nGamma\n"; string(ba) != want {
+		t.Errorf("expected the manifest\n%s\ngot\n%s", want, ba)
+	}
+
+	err = os.WriteFile(manifest, []byte("# curated\nAlpha\n\nGamma\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := generateTestdata(t, "manifest", "-manifest", manifest)
+
+	assertContains(t, source, `obj.Set("alpha", s.Alpha)`, `obj.Set("gamma", s.Gamma)`)
+
+	for _, name := range []string{"Beta", "Delta"} {
+		if strings.Contains(source, "manifest."+name+"(") {
+			t.Errorf("%s is bridged without being listed in the manifest", name)
+		}
+	}
+}
+
+func TestBrokenTemplate(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.tmpl")
+
+	err := os.WriteFile(broken, []byte("package {{ .OutputPkg }}\n\n{{ range .Funcs }\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runTestdata(t, "builtins", "-t", broken)
+	if err == nil || !strings.Contains(err.Error(), broken+" at line 3") {
+		t.Errorf("expected the error to name %s at line 3, got %v", broken, err)
+	}
+
+	// a broken partial of a template directory
+	partial := filepath.Join(dir, "partials", "header.tmpl")
+
+	err = os.MkdirAll(filepath.Dir(partial), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(partial, []byte("{{ define \"header\" }}package {{ .OutputPkg }}\n{{ if }}\n{{ end }}\n"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runTestdata(t, "builtins", "-t", filepath.Dir(partial))
+	if err == nil || !strings.Contains(err.Error(), partial+" at line 2 near \"{{ if }}\"") {
+		t.Errorf("expected the error to name %s at line 2, got %v", partial, err)
+	}
+}
+
+func TestUndeclaredTypes(t *testing.T) {
+	data, err := analyzeTestdata(t, "multifile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.indexFunc("Open") == -1 {
+		t.Errorf("Open returning the type of another file is not bridged")
+	}
+
+	reasons := make(map[string]string)
+	for _, skipped := range data.Skipped {
+		reasons[skipped.Name] = skipped.Reason
+	}
+
+	for name, want := range map[string]string{
+		"Load": "type Config is not declared in package multifile",
+		"Peek": "type handle is not exported by package multifile",
+	} {
+		if reasons[name] != want {
+			t.Errorf("expected %s to be skipped as %q, got %q", name, want, reasons[name])
+		}
+	}
+}
+
+func TestHashedNames(t *testing.T) {
+	// the directory is named like the package
+	dir := filepath.Join(t.TempDir(), "hashed")
+
+	err := os.Mkdir(dir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		"go.mod":       "module example.com/hashed\n\ngo 1.23\n",
+		"hashed.go":    "package hashed\n\nfunc Add(a, b int) int { return a + b }\n",
+		"unrelated.go": "package hashed\n\nfunc sub(a, b int) int { return a - b }\n",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hashedName := func() string {
+		t.Helper()
+
+		parseTestFlags(t, "-g", filepath.Join(dir, "go.mod"), "-n", "example.com/hashed", "-o", t.TempDir(), "-cache=false", "-hashed-names")
+
+		err := runAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		filenames, err := filepath.Glob(filepath.Join(*output, "goja_go_example_com_hashed", "goja_go_example_com_hashed_*.go"))
+		if err != nil || len(filenames) != 1 {
+			t.Fatalf("expected one hashed file, got %v %v", filenames, err)
+		}
+
+		return filepath.Base(filenames[0])
+	}
+
+	name := hashedName()
+
+	// unexported changes do not change the bridge
+	err = os.WriteFile(filepath.Join(dir, "unrelated.go"), []byte("package hashed\n\nfunc sub(a, b int) int { return b - a }\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other := hashedName(); other != name {
+		t.Errorf("expected the same input to yield %s, got %s", name, other)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "hashed.go"), []byte("package hashed\n\nfunc Add(a, b int64) int64 { return a + b }\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other := hashedName(); other == name {
+		t.Errorf("expected the changed input to yield another name than %s", name)
+	}
+}
+
+func TestSortOnce(t *testing.T) {
+	sorts := 0
+	sortAll := sortFuncs
+
+	sortFuncs = func(funcs []Func) {
+		sorts++
+		sortAll(funcs)
+	}
+
+	t.Cleanup(func() {
+		sortFuncs = sortAll
+	})
+
+	for _, name := range []string{"builtins", "unsorted"} {
+		sorts = 0
+
+		data, err := analyzeTestdata(t, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sorts != 1 {
+			t.Errorf("the functions of %s are sorted %d times", name, sorts)
+		}
+
+		if !slices.IsSortedFunc(data.Funcs, func(a, b Func) int { return strings.Compare(a.Name, b.Name) }) || !slices.IsSorted(data.Imports) {
+			t.Errorf("the functions or imports of %s are not sorted", name)
+		}
+	}
+
+	source := generateTestdata(t, "unsorted")
+
+	last := -1
+	for _, name := range []string{"alpha", "beta", "mid", "zeta"} {
+		i := strings.Index(source, fmt.Sprintf("obj.Set(%q", name))
+		if i < last {
+			t.Errorf("%s is registered out of order", name)
+		}
+
+		last = i
+	}
+}
+
+func TestStub(t *testing.T) {
+	runBridge(t, "mailer", bridgeTest{
+		Args: []string{"-stub"},
+		Go: `calls := []string{}
+
+	register(newBridge(vm, bridgeStubs{
+		Send: func(to string, body string) error {
+			calls = append(calls, to+": "+body)
+
+			return nil
+		},
+	}))
+
+	t.Cleanup(func() {
+		if want := "a@example.com: hello|b@example.com: bye"; strings.Join(calls, "|") != want {
+			t.Errorf("expected the stub to record %s, got %v", want, calls)
+		}
+	})`,
+		Script: `
+			bridge.send("a@example.com", "hello");
+			bridge.send("b@example.com", "bye");
+
+			let threw = false;
+			try {
+				bridge.pending();
+			} catch (e) {
+				threw = String(e).includes("Pending: function not stubbed");
+			}
+
+			if (!threw) {
+				throw new Error("pending does not throw without stub");
+			}`,
+	})
+}
+
+func TestGenericReceivers(t *testing.T) {
+	parseTestFlags(t, "-methods")
+
+	data, err := analyzeTestdata(t, "generics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.indexFunc("NewStack") == -1 || len(data.Types) != 0 {
+		t.Errorf("expected NewStack to be bridged without the generic type, got %v", data.Types)
+	}
+
+	reasons := make(map[string]string)
+	for _, skipped := range data.Skipped {
+		reasons[skipped.Kind+" "+skipped.Name] = skipped.Reason
+	}
+
+	for name, want := range map[string]string{
+		"method Stack.Len":  "methods of generic types are not supported",
+		"method Stack.Push": "methods of generic types are not supported",
+		"function Identity": "generic function without -instantiate",
+	} {
+		if reasons[name] != want {
+			t.Errorf("expected %s to be skipped as %q, got %q", name, want, reasons[name])
+		}
+	}
+
+	runBridge(t, "generics", bridgeTest{
+		Args:   []string{"-methods"},
+		Script: `bridge.newStack()`,
+	})
+}
+
+func TestImportAlias(t *testing.T) {
+	source := generateTestdata(t, "errors")
+
+	assertContains(t, source, `bridgepkg_errors "`+testdataPkg+`errors"`, `"errors"`, "bridgepkg_errors.Wrap(")
+
+	runBridge(t, "errors", bridgeTest{
+		Script: `
+			if (bridge.wrap("x").Msg !== "wrapped: x") {
+				throw new Error("wrap returns " + JSON.stringify(bridge.wrap("x")));
+			}`,
+	})
+
+	// the packages are named like those of the wrappers and helpers enabled by the flags
+	for _, args := range [][]string{nil, {"-methods"}, {"-int64", "bigint", "-runes", "-metrics"}} {
+		buildBridges(t, "fmt,errors,bytes,strings,time", args...)
+	}
+}
+
+func TestVerifyExcludedByPackage(t *testing.T) {
+	verifyExcluded = map[string]bool{verifyKey(testdataPkg+"manifest", "Alpha"): true}
+
+	t.Cleanup(func() {
+		verifyExcluded = make(map[string]bool)
+	})
+
+	data, err := analyzeTestdata(t, "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.indexFunc("Alpha") != -1 || len(data.Skipped) != 1 || data.Skipped[0].Reason != "failed to compile" {
+		t.Errorf("expected Alpha to be skipped as failed to compile, got %v", data.Skipped)
+	}
+
+	// the same name in another package is no failed function
+	data, err = analyzeTestdata(t, "unsorted")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.indexFunc("Alpha") == -1 || len(data.Skipped) != 0 {
+		t.Errorf("expected Alpha of another package to be bridged, got %v", data.Skipped)
+	}
+}
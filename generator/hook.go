@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runHook passes the package data as JSON to the -hook command and replaces the fields contained
+// in the JSON it writes, so the command may rename, drop or add functions, types and values and
+// add Extra data for custom templates. The import path of the package is in GOJA_GO_PACKAGE.
+func (data *Data) runHook() error {
+	args := strings.Fields(*hook)
+	if len(args) == 0 {
+		return nil
+	}
+
+	ba, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "GOJA_GO_PACKAGE="+data.pkgPath)
+	cmd.Stdin = bytes.NewReader(ba)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("hook %s failed for package %s: %w", *hook, data.pkgPath, err)
+	}
+
+	err = json.Unmarshal(output, data)
+	if err != nil {
+		return fmt.Errorf("invalid output of hook %s for package %s: %w", *hook, data.pkgPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+)
+
+// qualifier rewrites the types of the declarations of a file
+type qualifier struct {
+	data *Data
+	// declared are the types declared by the package
+	declared map[string]bool
+	// names are the package names of the imports named by an alias or dot imported
+	names map[string]string
+	// packages are the imported packages, if known from type checking
+	packages map[string]*types.Package
+	// aliases are the import paths by the alias they are imported with
+	aliases map[string]string
+	// dots are the paths of the dot imports
+	dots []string
+}
+
+// qualifyImports rewrites the types in the declarations of the files which refer to packages
+// imported under an alias or by a dot import to identifiers qualified by the names of the
+// packages, as the generated code imports all packages without alias
+func (data *Data) qualifyImports(files map[string]*ast.File) {
+	declared := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					declared[spec.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		q := &qualifier{
+			data:     data,
+			declared: declared,
+			names:    make(map[string]string),
+			packages: make(map[string]*types.Package),
+			aliases:  make(map[string]string),
+		}
+
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || spec.Name == nil || spec.Name.Name == "_" {
+				continue
+			}
+
+			q.names[path] = data.importName(path)
+
+			var obj types.Object
+			if data.info != nil {
+				obj = data.info.Defs[spec.Name]
+				if obj == nil {
+					obj = data.info.Implicits[spec]
+				}
+			}
+
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				q.packages[path] = pkgName.Imported()
+				q.names[path] = pkgName.Imported().Name()
+			}
+
+			if spec.Name.Name == "." {
+				q.dots = append(q.dots, path)
+			} else {
+				q.aliases[spec.Name.Name] = path
+			}
+		}
+
+		if len(q.names) == 0 {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				q.fields(decl.Recv)
+				q.expr(decl.Type)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						q.fields(spec.TypeParams)
+						spec.Type = q.expr(spec.Type)
+					case *ast.ValueSpec:
+						if spec.Type != nil {
+							spec.Type = q.expr(spec.Type)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (q *qualifier) fields(fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+
+	for _, field := range fields.List {
+		field.Type = q.expr(field.Type)
+	}
+}
+
+// expr returns the type expr with the aliases replaced by package names and the identifiers of
+// dot imported types qualified
+func (q *qualifier) expr(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if path := q.dotImport(t); path != "" {
+			x := ast.NewIdent(q.names[path])
+			x.NamePos = t.Pos()
+
+			if pkg := q.packages[path]; pkg != nil {
+				q.data.info.Uses[x] = types.NewPkgName(t.Pos(), nil, pkg.Name(), pkg)
+			}
+
+			return &ast.SelectorExpr{X: x, Sel: t}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if path, ok := q.aliases[x.Name]; ok {
+				x.Name = q.names[path]
+			}
+		}
+	case *ast.StarExpr:
+		t.X = q.expr(t.X)
+	case *ast.ParenExpr:
+		t.X = q.expr(t.X)
+	case *ast.Ellipsis:
+		t.Elt = q.expr(t.Elt)
+	case *ast.ArrayType:
+		t.Elt = q.expr(t.Elt)
+	case *ast.MapType:
+		t.Key = q.expr(t.Key)
+		t.Value = q.expr(t.Value)
+	case *ast.ChanType:
+		t.Value = q.expr(t.Value)
+	case *ast.IndexExpr:
+		t.X = q.expr(t.X)
+		t.Index = q.expr(t.Index)
+	case *ast.IndexListExpr:
+		t.X = q.expr(t.X)
+		for i, index := range t.Indices {
+			t.Indices[i] = q.expr(index)
+		}
+	case *ast.FuncType:
+		q.fields(t.TypeParams)
+		q.fields(t.Params)
+		q.fields(t.Results)
+	case *ast.StructType:
+		q.fields(t.Fields)
+	case *ast.InterfaceType:
+		q.fields(t.Methods)
+	}
+
+	return expr
+}
+
+// dotImport returns the path of the dot import declaring the type ident refers to, empty if
+// it is declared otherwise. Without type information, an exported type not declared by the
+// package is attributed to the only dot import of the file.
+func (q *qualifier) dotImport(ident *ast.Ident) string {
+	if len(q.dots) == 0 {
+		return ""
+	}
+
+	if q.data.info != nil {
+		if obj, ok := q.data.info.Uses[ident].(*types.TypeName); ok && obj.Pkg() != nil && slices.Contains(q.dots, obj.Pkg().Path()) {
+			return obj.Pkg().Path()
+		}
+
+		if q.data.info.Uses[ident] != nil {
+			return ""
+		}
+	}
+
+	if len(q.dots) != 1 || !ident.IsExported() || q.declared[ident.Name] || slices.Contains(predeclaredTypes, ident.Name) {
+		return ""
+	}
+
+	return q.dots[0]
+}
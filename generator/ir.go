@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/mpetavy/common"
+)
+
+const irVersion = 1
+
+// IR is the scan result of the generated packages for tools consuming it instead of parsing Go,
+// with the packages in the JSON format the -hook command reads
+type IR struct {
+	Version  int
+	Packages []IRPackage
+}
+
+type IRPackage struct {
+	Path string
+	*Data
+}
+
+func saveIR(datas []*Data) error {
+	if *ir == "" {
+		return nil
+	}
+
+	r := IR{
+		Version:  irVersion,
+		Packages: []IRPackage{},
+	}
+
+	for _, data := range datas {
+		r.Packages = append(r.Packages, IRPackage{
+			Path: data.pkgPath,
+			Data: data,
+		})
+	}
+
+	ba, err := json.MarshalIndent(r, "", "  ")
+	if common.Error(err) {
+		return err
+	}
+
+	return writeFile(*ir, append(ba, '\n'))
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mpetavy/common"
+)
+
+var (
+	// resolveMu serializes resolving packages, which locates and caches the go.mod and vendor
+	// information shared by the packages generated in parallel
+	resolveMu sync.Mutex
+	goEnvMu   sync.Mutex
+	watchMu   sync.Mutex
+	warnMu    sync.Mutex
+)
+
+// parallel calls fn for 0 to count-1 on up to -parallel goroutines and returns the first error
+func parallel(count int, fn func(i int) error) error {
+	workers := max(1, min(*parallelism, count))
+
+	indices := make(chan int)
+	errs := make([]error, count)
+
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := range count {
+		indices <- i
+	}
+
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warn logs a warning, which common drops while another goroutine logs
+func warn(format string, args ...any) {
+	if jsonDiagnostics() {
+		diagnose(Diagnostic{Level: "warning", Message: fmt.Sprintf(format, args...)})
+
+		return
+	}
+
+	warnMu.Lock()
+	defer warnMu.Unlock()
+
+	common.Warn(format, args...)
+}
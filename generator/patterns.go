@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mpetavy/common"
+)
+
+// patternSuffix selects a package together with all its subpackages like the go command does
+const patternSuffix = "/..."
+
+// expandPattern returns the packages matched by pattern, which are its root package and the
+// subpackages except internal, testdata and vendor ones, those of nested modules and the output
+func expandPattern(pattern string) ([]string, error) {
+	pattern, version := splitVersion(pattern)
+	root := strings.TrimSuffix(pattern, patternSuffix)
+
+	suffix := ""
+	if version != "" {
+		suffix = "@" + version
+	}
+
+	dir, _, err := findPackagePath(root + suffix)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	// compared to the absolute output directory while walking
+	dir, err = filepath.Abs(dir)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	outputDir, err := filepath.Abs(*output)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	names := []string{}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if p != dir {
+			name := d.Name()
+			if name == "internal" || name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+
+			if common.FileExists(filepath.Join(p, "go.mod")) || p == outputDir {
+				return filepath.SkipDir
+			}
+		}
+
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err == nil && filter(info) {
+				// commands cannot be imported by the bridge
+				file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(p, entry.Name()), nil, parser.PackageClauseOnly)
+				if err != nil || file.Name.Name == "main" {
+					break
+				}
+
+				rel, err := filepath.Rel(dir, p)
+				if err != nil {
+					return err
+				}
+
+				names = append(names, path.Join(root, filepath.ToSlash(rel))+suffix)
+
+				break
+			}
+		}
+
+		return nil
+	})
+	if common.Error(err) {
+		return nil, err
+	}
+
+	return names, nil
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandPatternSkipsOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                                "module example.com/host\n\ngo 1.23\n",
+		"host.go":                               "package host\n",
+		"sub/sub.go":                            "package sub\n",
+		"bridges/goja_go_example_com_host/b.go": "package goja_go_example_com_host\n",
+		"bridges/goja_go_example_com_host_sub/b.go": "package goja_go_example_com_host_sub\n",
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(filename), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filename, []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	// a relative go.mod and output directory like on the command line
+	parseTestFlags(t, "-g", "go.mod", "-o", "bridges")
+
+	names, err := expandPattern("example.com/host/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(names, []string{"example.com/host", "example.com/host/sub"}) {
+		t.Errorf("expected the host packages without the generated ones, got %v", names)
+	}
+}
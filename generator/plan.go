@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// printPlan prints what would be bridged for data by -dry-run
+func printPlan(w io.Writer, data *Data) {
+	fmt.Fprintf(w, "package %s\n", data.pkgPath)
+	fmt.Fprintf(w, "  output: %s\n", data.Filename)
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+
+		fmt.Fprintf(w, "  %s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+
+	funcs := []string{}
+	for _, f := range data.Funcs {
+		line := fmt.Sprintf("%s as %s", f.Name, f.JsName)
+		if f.Async != nil {
+			line += fmt.Sprintf(" and %sAsync", f.JsName)
+		}
+
+		funcs = append(funcs, line)
+	}
+
+	section("functions", funcs)
+
+	types := []string{}
+	for _, t := range data.Types {
+		line := t.Name
+
+		methods := []string{}
+		for _, m := range t.Methods {
+			methods = append(methods, m.Name)
+		}
+
+		for _, p := range t.Properties {
+			methods = append(methods, p.Get.Name, p.Set.Name)
+		}
+
+		if len(methods) > 0 {
+			line += fmt.Sprintf(" (methods %s)", strings.Join(methods, ", "))
+		}
+
+		types = append(types, line)
+	}
+
+	section("types", types)
+
+	consts := []string{}
+	vars := []string{}
+	for _, group := range data.Values {
+		for _, value := range group {
+			if value.Const {
+				consts = append(consts, value.Name)
+			} else {
+				vars = append(vars, value.Name)
+			}
+		}
+	}
+
+	section("constants", consts)
+	section("variables", vars)
+
+	skipped := []string{}
+	for _, skip := range data.Skipped {
+		line := fmt.Sprintf("%s %s: %s", skip.Kind, skip.Name, skip.Reason)
+		if skip.Position != "" {
+			line += fmt.Sprintf(" (%s)", skip.Position)
+		}
+
+		skipped = append(skipped, line)
+	}
+
+	section("skipped", skipped)
+}
+
+// dryRunFile reports the file which -dry-run does not write
+func dryRunFile(filename string) {
+	fmt.Printf("%s (not written)\n", filename)
+}
+
+// printList prints the API surface of data with the Go signatures and the JS names and types
+// they are bridged as by the list subcommand
+func printList(w io.Writer, data *Data) {
+	fmt.Fprintf(w, "package %s\n", data.pkgPath)
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+
+		fmt.Fprintf(w, "  %s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+
+	signature := func(f Func) string {
+		return fmt.Sprintf("%s%s as %s(%s): %s", f.Name, strings.TrimSpace(strings.TrimPrefix(f.Signature, "func")), f.JsName, f.TsParams, f.TsResult)
+	}
+
+	funcs := []string{}
+	for _, f := range data.Funcs {
+		line := signature(f)
+		if f.Async != nil {
+			line += fmt.Sprintf(" and %sAsync", f.JsName)
+		}
+
+		funcs = append(funcs, line)
+	}
+
+	section("functions", funcs)
+
+	types := []string{}
+	for _, t := range data.Types {
+		types = append(types, fmt.Sprintf("%s as new %s(%s)", t.Type, t.Name, t.TsParams))
+
+		for _, field := range t.Fields {
+			types = append(types, fmt.Sprintf("  field %s as %s: %s", field.Name, field.JsName, field.Ts))
+		}
+
+		for _, m := range t.Methods {
+			types = append(types, "  method "+signature(m))
+		}
+
+		for _, p := range t.Properties {
+			types = append(types, fmt.Sprintf("  property %s/%s as %s: %s", p.Get.Name, p.Set.Name, p.JsName, p.Ts))
+		}
+
+		if t.Stringer {
+			types = append(types, "  method String() string as toString(): string")
+		}
+	}
+
+	section("types", types)
+
+	consts := []string{}
+	vars := []string{}
+	for _, group := range data.Values {
+		for _, value := range group {
+			line := fmt.Sprintf("%s as %s: %s", value.Name, value.Name, value.Ts)
+			if value.Const {
+				consts = append(consts, line)
+			} else {
+				vars = append(vars, line)
+			}
+		}
+	}
+
+	section("constants", consts)
+	section("variables", vars)
+
+	enums := []string{}
+	for _, enum := range data.Enums {
+		names := []string{}
+		for _, value := range enum.Values {
+			names = append(names, value.Name)
+		}
+
+		enums = append(enums, fmt.Sprintf("%s as %s: %s", enum.Name, enum.Name, strings.Join(names, ", ")))
+	}
+
+	section("enums", enums)
+}
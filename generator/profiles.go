@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"embed"
+	"fmt"
+	"text/template"
+)
+
+//go:embed profiles/*.tmpl
+var profileFiles embed.FS
+
+// profile specializes the bridge of a package by a template defining the blocks profile,
+// profileRegister, profileDts and profileDtsTypes left empty by the built-in template
+type profile struct {
+	name    string
+	helpers []string
+	imports []string
+	// jsNames are registered on the bridge object besides the bridged symbols
+	jsNames []string
+}
+
+// profiles are the built-in profiles by the packages they specialize
+var profiles = map[string]profile{
+	"net/http": {
+		name:    "net_http",
+		helpers: []string{"async", "bytes", "context"},
+		imports: []string{"bytes", "fmt", "io", "net/http", "sort", "strings", "sync"},
+		jsNames: []string{"fetch", "handler"},
+	},
+}
+
+// applyProfile selects the built-in profile of the package with the helpers and imports it uses
+func (data *Data) applyProfile() {
+	p, ok := profiles[data.pkgPath]
+	if !*profilesFlag || !ok {
+		return
+	}
+
+	data.Profile = p.name
+
+	for _, helper := range p.helpers {
+		data.useHelper(helper)
+	}
+
+	for _, path := range p.imports {
+		data.addImportPath(path)
+	}
+}
+
+// parseProfile adds the blocks of the built-in profile name to t
+func parseProfile(t *template.Template, name string) error {
+	ba, err := profileFiles.ReadFile("profiles/" + name + ".tmpl")
+	if err != nil {
+		return fmt.Errorf("unknown profile %s: %w", name, err)
+	}
+
+	_, err = t.New(name).Parse(string(ba))
+
+	return err
+}
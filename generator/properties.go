@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Property is a getter X() or GetX() of a type with its setter SetX(v), which the JS objects of
+// the type expose as accessor property instead of two methods
+type Property struct {
+	Name   string
+	JsName string
+	Get    Func
+	Set    Func
+	Ts     string
+}
+
+// propertiesEnabled reports whether the getter and setter pairs of the type are bridged as
+// properties, by the config file if it lists the type or else by -properties
+func propertiesEnabled(typeName string) bool {
+	if enabled, ok := configProperties[typeName]; ok {
+		return enabled
+	}
+
+	return *properties
+}
+
+// scanProperties moves the getter and setter pairs of the bridged methods of typ to its properties
+func (data *Data) scanProperties(typ *Type) {
+	if !propertiesEnabled(typ.Name) {
+		return
+	}
+
+	decls := make(map[string]*ast.FuncDecl)
+	for _, fd := range data.methods[typ.Name] {
+		decls[fd.Name.Name] = fd
+	}
+
+	for _, set := range slices.Clone(typ.Methods) {
+		name, ok := strings.CutPrefix(set.Name, "Set")
+		if !ok || name == "" || !unicode.IsUpper([]rune(name)[0]) {
+			continue
+		}
+
+		valueType, ok := setterType(decls[set.Name])
+		if !ok || !slices.ContainsFunc(typ.Methods, func(m Func) bool { return m.Name == set.Name }) {
+			continue
+		}
+
+		for _, getName := range []string{name, "Get" + name} {
+			i := slices.IndexFunc(typ.Methods, func(m Func) bool { return m.Name == getName })
+			if i == -1 || getterType(decls[getName]) != valueType {
+				continue
+			}
+
+			get := typ.Methods[i]
+
+			property := Property{
+				Name:   name,
+				JsName: jsName(name),
+				Get:    get,
+				Set:    set,
+				Ts:     get.TsResult,
+			}
+
+			// a getter named like the property keeps its JS name, which may be renamed
+			if getName == name {
+				property.JsName = get.JsName
+			}
+
+			typ.Properties = append(typ.Properties, property)
+
+			typ.Methods = slices.DeleteFunc(typ.Methods, func(m Func) bool {
+				return m.Name == get.Name || m.Name == set.Name
+			})
+
+			break
+		}
+	}
+}
+
+// getterType returns the type of the only result of the method taking no parameters, empty if
+// the method is no getter
+func getterType(fd *ast.FuncDecl) string {
+	if fd == nil || fd.Type.Params.NumFields() != 0 || fd.Type.Results.NumFields() != 1 {
+		return ""
+	}
+
+	typ := types.ExprString(fd.Type.Results.List[0].Type)
+	if typ == "error" {
+		return ""
+	}
+
+	return typ
+}
+
+// setterType returns the type of the only parameter of the method returning nothing or an error
+func setterType(fd *ast.FuncDecl) (string, bool) {
+	if fd == nil || fd.Type.Params.NumFields() != 1 {
+		return "", false
+	}
+
+	if n := fd.Type.Results.NumFields(); n > 1 || (n == 1 && types.ExprString(fd.Type.Results.List[0].Type) != "error") {
+		return "", false
+	}
+
+	if _, ok := fd.Type.Params.List[0].Type.(*ast.Ellipsis); ok {
+		return "", false
+	}
+
+	return types.ExprString(fd.Type.Params.List[0].Type), true
+}
@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"sort"
+
+	"github.com/mpetavy/common"
+)
+
+const reportVersion = 1
+
+type ReportCategory struct {
+	BridgedCount int      `json:"bridgedCount"`
+	SkippedCount int      `json:"skippedCount"`
+	Bridged      []string `json:"bridged"`
+	Skipped      []Skip   `json:"skipped"`
+}
+
+type Report struct {
+	Version   int            `json:"version"`
+	Package   string         `json:"package"`
+	Functions ReportCategory `json:"functions"`
+	Structs   ReportCategory `json:"structs"`
+	Consts    ReportCategory `json:"consts"`
+	Vars      ReportCategory `json:"vars"`
+}
+
+func (category *ReportCategory) add(bridged string, skipped *Skip) {
+	if skipped != nil {
+		category.Skipped = append(category.Skipped, *skipped)
+		category.SkippedCount++
+
+		return
+	}
+
+	category.Bridged = append(category.Bridged, bridged)
+	category.BridgedCount++
+}
+
+func newReport(data *Data) *Report {
+	r := &Report{
+		Version: reportVersion,
+		Package: data.pkgPath,
+	}
+
+	for _, category := range []*ReportCategory{&r.Functions, &r.Structs, &r.Consts, &r.Vars} {
+		category.Bridged = []string{}
+		category.Skipped = []Skip{}
+	}
+
+	for _, f := range data.Funcs {
+		r.Functions.add(f.Name, nil)
+	}
+
+	for _, skip := range data.Skipped {
+		switch skip.Kind {
+		case "function":
+			r.Functions.add("", &skip)
+		case "struct":
+			r.Structs.add("", &skip)
+		case "const":
+			r.Consts.add("", &skip)
+		case "var":
+			r.Vars.add("", &skip)
+		}
+	}
+
+	bridged := make(map[string]bool)
+	for _, typ := range data.Types {
+		bridged[typ.Name] = true
+	}
+
+	names := []string{}
+	for name, spec := range data.types {
+		if _, ok := spec.Type.(*ast.StructType); ok && ast.IsExported(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch {
+		case bridged[name]:
+			r.Structs.add(name, nil)
+		case !*methodsFlag:
+			r.Structs.add("", &Skip{Kind: "struct", Name: name, Reason: "struct bridging requires -methods"})
+		default:
+			r.Structs.add("", &Skip{Kind: "struct", Name: name, Reason: "struct without bridged methods"})
+		}
+	}
+
+	for _, group := range data.Values {
+		for _, value := range group {
+			if value.Const {
+				r.Consts.add(value.Name, nil)
+			} else {
+				r.Vars.add(value.Name, nil)
+			}
+		}
+	}
+
+	return r
+}
+
+func saveReport(data *Data) error {
+	if *report == "" {
+		return nil
+	}
+
+	if *report != "json" {
+		return fmt.Errorf("unsupported report format: %s", *report)
+	}
+
+	ba, err := json.MarshalIndent(newReport(data), "", "  ")
+	if common.Error(err) {
+		return err
+	}
+
+	filename := *reportFile
+	if filename == "" {
+		filename = filepath.Join(filepath.Dir(data.Filename), "report.json")
+	}
+
+	return writeFile(filename, append(ba, '\n'))
+}
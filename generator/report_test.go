@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// readReport generates the bridge and the coverage report of the package testdata/name
+func readReport(t *testing.T, name string, args ...string) Report {
+	t.Helper()
+
+	err := runTestdata(t, name, append([]string{"-report", "json"}, args...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := os.ReadFile(filepath.Join(filepath.Dir(bridgeFilename(name)), "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r Report
+
+	err = json.Unmarshal(ba, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+// assertCategory fails the test if the category does not list exactly the bridged names and the
+// skipped names with their reasons
+func assertCategory(t *testing.T, kind string, category ReportCategory, bridged []string, skipped map[string]string) {
+	t.Helper()
+
+	if category.BridgedCount != len(bridged) || !slices.Equal(category.Bridged, bridged) {
+		t.Errorf("expected the bridged %s %v, got %d %v", kind, bridged, category.BridgedCount, category.Bridged)
+	}
+
+	if category.SkippedCount != len(skipped) || len(category.Skipped) != len(skipped) {
+		t.Errorf("expected the skipped %s %v, got %d %v", kind, skipped, category.SkippedCount, category.Skipped)
+	}
+
+	for _, skip := range category.Skipped {
+		if reason, ok := skipped[skip.Name]; !ok || skip.Reason != reason {
+			t.Errorf("unexpected skipped %s %s: %s", kind, skip.Name, skip.Reason)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := readReport(t, "mixed")
+
+	if r.Version != reportVersion || r.Package != testdataPkg+"mixed" {
+		t.Errorf("unexpected report version %d of package %s", r.Version, r.Package)
+	}
+
+	assertCategory(t, "functions", r.Functions, []string{"Area", "NewCircle"}, map[string]string{"Address": "unsafe type unsafe.Pointer"})
+	assertCategory(t, "structs", r.Structs, []string{}, map[string]string{"Circle": "struct bridging requires -methods"})
+	assertCategory(t, "consts", r.Consts, []string{"Pi", "Label"}, nil)
+	assertCategory(t, "vars", r.Vars, []string{"Count"}, nil)
+
+	r = readReport(t, "mixed", "-methods")
+
+	assertCategory(t, "structs", r.Structs, []string{"Circle"}, nil)
+}
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// helperDecls returns the printed top-level declarations of src by their names, which are
+// normalized by rename like all identifiers the declarations refer to
+func helperDecls(t *testing.T, fset *token.FileSet, files []*ast.File, rename func(string) string) map[string]string {
+	t.Helper()
+
+	decls := make(map[string]string)
+
+	var visit func(node ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			// selected fields and methods keep their names
+			ast.Inspect(node.X, visit)
+
+			return false
+		case *ast.Ident:
+			node.Name = rename(node.Name)
+		}
+
+		return true
+	}
+
+	add := func(name string, node ast.Node) {
+		ast.Inspect(node, visit)
+
+		var buffer bytes.Buffer
+
+		err := printer.Fprint(&buffer, fset, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decls[rename(name)] = buffer.String()
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				add(decl.Name.Name, decl)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						add(spec.Name.Name, spec)
+					case *ast.ValueSpec:
+						add(spec.Names[0].Name, spec)
+					}
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+// TestRuntimeMatchesTemplate asserts that the helpers of the runtime package are those the built-in
+// template generates without -shared-runtime, apart from the bridge prefix of their names
+func TestRuntimeMatchesTemplate(t *testing.T) {
+	tmpl, err := loadTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &Data{StructName: "Bridge", Helpers: make(map[string]bool)}
+
+	for _, match := range regexp.MustCompile(`index \.Helpers "(\w+)"`).FindAllStringSubmatch(defaultTmpl, -1) {
+		if !slices.Contains(localHelpers, match[1]) {
+			data.Helpers[match[1]] = true
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	err = tmpl.ExecuteTemplate(&buffer, "helpers", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+
+	generated, err := parser.ParseFile(fset, "helpers.go", "package helpers\n"+buffer.String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filenames, err := filepath.Glob(filepath.Join("..", "runtime", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runtimeFiles := []*ast.File{}
+	runtimeNames := []string{}
+
+	for _, filename := range filenames {
+		file, err := parser.ParseFile(fset, filename, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name := range file.Scope.Objects {
+			runtimeNames = append(runtimeNames, name)
+		}
+
+		runtimeFiles = append(runtimeFiles, file)
+	}
+
+	// the names of the runtime package are compared like the generated ones without their prefix
+	normalize := strings.ToLower
+
+	generatedDecls := helperDecls(t, fset, []*ast.File{generated}, func(name string) string {
+		if rest, ok := strings.CutPrefix(name, "bridge"); ok && rest != "" {
+			return normalize(rest)
+		}
+
+		return name
+	})
+
+	runtimeDecls := helperDecls(t, fset, runtimeFiles, func(name string) string {
+		if slices.Contains(runtimeNames, name) && name != "ErrInterrupted" {
+			return normalize(name)
+		}
+
+		return name
+	})
+
+	delete(runtimeDecls, "packageisversion1")
+
+	for _, name := range sortedKeys(runtimeDecls) {
+		if generatedDecls[name] != runtimeDecls[name] {
+			t.Errorf("runtime declaration %s differs from the template:\n%s\n\n%s", name, runtimeDecls[name], generatedDecls[name])
+		}
+	}
+
+	for _, name := range sortedKeys(generatedDecls) {
+		if _, ok := runtimeDecls[name]; !ok {
+			t.Errorf("template declaration %s is missing in the runtime package", name)
+		}
+	}
+}
+
+// TestSharedRuntimeBuilds asserts that the bridges taking streams and hex strings import only the
+// packages used besides the runtime package
+func TestSharedRuntimeBuilds(t *testing.T) {
+	buildBridges(t, "bufio,compress/gzip,encoding/csv,encoding/json,encoding/base64,text/template", "-shared-runtime", "-bytes-as-hex")
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mpetavy/common"
+)
+
+// splitSuffix ends the names of the files the wrappers of each source file are split into
+const splitSuffix = "_gen.go"
+
+// splitSources returns the source files declaring the bridged functions and types
+func (data *Data) splitSources() []string {
+	sources := []string{}
+	for _, f := range data.Funcs {
+		if !slices.Contains(sources, f.File) {
+			sources = append(sources, f.File)
+		}
+	}
+
+	for _, t := range data.Types {
+		if !slices.Contains(sources, t.File) {
+			sources = append(sources, t.File)
+		}
+	}
+
+	sort.Strings(sources)
+
+	return sources
+}
+
+// splitFilename returns the file the wrappers declared in the source file are split into
+func (data *Data) splitFilename(source string) string {
+	return filepath.Join(filepath.Dir(data.Filename), strings.TrimSuffix(filepath.Base(source), ".go")+splitSuffix)
+}
+
+// splitFiles renders the wrappers of the functions and types declared in each source file into
+// a file named after it next to the main generated file
+func (data *Data) splitFiles(tmpl *template.Template, stamp string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	for _, source := range data.splitSources() {
+		file := *data
+		file.Funcs = slices.DeleteFunc(slices.Clone(data.Funcs), func(f Func) bool {
+			return f.File != source
+		})
+		file.Types = slices.DeleteFunc(slices.Clone(data.Types), func(t Type) bool {
+			return t.File != source
+		})
+
+		buffer := bytes.Buffer{}
+
+		err := tmpl.ExecuteTemplate(&buffer, "file", &file)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		ba, err := addHeader(buffer.Bytes())
+		if common.Error(err) {
+			return nil, err
+		}
+
+		filename := data.splitFilename(source)
+
+		ba, err = formatSource(filename, addStamp(ba, stamp))
+		if common.Error(err) {
+			return nil, err
+		}
+
+		ba, err = data.pruneImports(filename, ba)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		files[filename] = ba
+	}
+
+	return files, nil
+}
+
+// pruneImports removes the imports the generated file src does not refer to
+func (data *Data) pruneImports(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+
+		gd.Specs = slices.DeleteFunc(gd.Specs, func(s ast.Spec) bool {
+			spec := s.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(spec.Path.Value)
+
+			name := data.importName(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			} else if _, ok := data.ImportAliases[path]; ok {
+				// the generator's own import of the path of the aliased bridged package
+				name = path[strings.LastIndex(path, "/")+1:]
+			}
+
+			return !used[name]
+		})
+	}
+
+	buffer := bytes.Buffer{}
+
+	err = format.Node(&buffer, fset, file)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// removeStaleSplits removes the files split from source files by an earlier run which are not
+// written by this one
+func (data *Data) removeStaleSplits(files map[string][]byte) error {
+	if *dryRun {
+		return nil
+	}
+
+	stale, err := filepath.Glob(filepath.Join(filepath.Dir(data.Filename), "*"+splitSuffix))
+	if common.Error(err) {
+		return err
+	}
+
+	for _, path := range stale {
+		if _, ok := files[path]; ok {
+			continue
+		}
+
+		ba, err := os.ReadFile(path)
+		if common.Error(err) {
+			return err
+		}
+
+		// leave files alone not declaring wrappers of the bridge
+		if !bytes.Contains(ba, []byte("func (bridge *"+data.StructName+")")) {
+			continue
+		}
+
+		if subcommand == "check" {
+			checkRemoved(path)
+
+			continue
+		}
+
+		err = os.Remove(path)
+		if common.Error(err) {
+			return err
+		}
+	}
+
+	return nil
+}
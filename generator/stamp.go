@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stampPrefix starts the comment line recording the inputs a file was generated from
+const stampPrefix = "// goja_go inputs: "
+
+// inputStamp returns the hash of the inputs of the files generated for the package with the
+// input key, adding the template and the header which only affect the writing of them
+func inputStamp(key string) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%s\n", key)
+
+	if *tmpl == "" {
+		fmt.Fprintf(h, "%s\n", defaultTmpl)
+	}
+
+	for _, filename := range templateFiles() {
+		hashFile(h, filename)
+	}
+
+	// the built-in profiles are added to any template
+	for _, pkg := range sortedKeys(profiles) {
+		ba, _ := profileFiles.ReadFile("profiles/" + profiles[pkg].name + ".tmpl")
+		fmt.Fprintf(h, "%s\n", ba)
+	}
+
+	if *headerFile != "" {
+		hashFile(h, *headerFile)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func addStamp(ba []byte, stamp string) []byte {
+	return append([]byte(stampPrefix+stamp+"\n\n"), ba...)
+}
+
+// readStamp returns the inputs recorded in the leading comments of a file written by an earlier
+// run, empty if there is none
+func readStamp(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if stamp, ok := strings.CutPrefix(line, stampPrefix); ok {
+			return stamp
+		}
+
+		if line != "" && !strings.HasPrefix(line, "//") {
+			break
+		}
+	}
+
+	return ""
+}
+
+// upToDate reports whether every file of the bridge was written from the inputs of the stamp
+// by an earlier run, so rewriting them would only touch their modification times
+func (data *Data) upToDate(stamp string) bool {
+	filenames := []string{data.Filename}
+
+	if data.Split {
+		for _, source := range data.splitSources() {
+			filenames = append(filenames, data.splitFilename(source))
+		}
+	}
+
+	if *dts {
+		filenames = append(filenames, data.dtsFilename())
+	}
+
+	if *tests {
+		filenames = append(filenames, data.testFilename())
+	}
+
+	for _, filename := range filenames {
+		if readStamp(filename) != stamp {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (data *Data) dtsFilename() string {
+	return strings.TrimSuffix(data.Filename, ".go") + ".d.ts"
+}
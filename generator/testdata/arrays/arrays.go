@@ -0,0 +1,13 @@
+// Package arrays declares functions taking fixed-length arrays, which the
+// generated test calls with arrays of their length.
+package arrays
+
+import "crypto/sha256"
+
+const Size = 2
+
+func Hash(b [4]byte) [4]byte { return b }
+
+func Digest(d [sha256.Size]byte) bool { return d == [sha256.Size]byte{} }
+
+func Grid(g [Size][Size]int) int { return g[0][0] }
@@ -0,0 +1,30 @@
+// Package builder declares a builder whose methods return their receiver for chaining.
+package builder
+
+import "errors"
+
+type Builder struct {
+	sum int
+}
+
+func NewBuilder() *Builder { return &Builder{} }
+
+func (b *Builder) Add(x int) *Builder {
+	b.sum += x
+
+	return b
+}
+
+// Check returns the receiver unless the sum is negative
+func (b *Builder) Check() (*Builder, error) {
+	if b.sum < 0 {
+		return nil, errors.New("negative sum")
+	}
+
+	return b, nil
+}
+
+// Reset ends a chain without results
+func (b *Builder) Reset() { b.sum = 0 }
+
+func (b *Builder) Sum() int { return b.sum }
@@ -0,0 +1,26 @@
+// Package builtins declares functions whose names and parameters shadow Go
+// builtins, imported packages and identifiers used by generated wrappers.
+package builtins
+
+import (
+	"fmt"
+	"time"
+)
+
+type Type struct {
+	Len int
+}
+
+func Len(s []int) int { return len(s) }
+
+func Cap(len []int) int { return cap(len) }
+
+func Append(append []int, new int) []int { return []int{new} }
+
+func New(err error) *Type { return &Type{} }
+
+func Types(bridge *Type, bridgeArg0 *Type, bridgeRes0 int) []*Type { return nil }
+
+func Wait(time time.Duration) {}
+
+func Stringer(fmt fmt.Stringer, goja string, _ int, builtins int) string { return goja }
@@ -0,0 +1,10 @@
+// Package commaok declares a lookup by the comma-ok idiom.
+package commaok
+
+var values = map[string]string{"a": "A"}
+
+func Lookup(key string) (string, bool) {
+	v, ok := values[key]
+
+	return v, ok
+}
@@ -0,0 +1,10 @@
+// Package durations declares functions taking and returning time.Duration.
+package durations
+
+import "time"
+
+var slept time.Duration
+
+func Sleep(d time.Duration) { slept = d }
+
+func Slept() time.Duration { return slept }
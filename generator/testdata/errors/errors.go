@@ -0,0 +1,9 @@
+// Package errors is named like a package the generated code imports, so the bridge imports it
+// under an alias.
+package errors
+
+type Error struct {
+	Msg string
+}
+
+func Wrap(msg string) *Error { return &Error{Msg: "wrapped: " + msg} }
@@ -0,0 +1,8 @@
+// Package frozen declares a struct bridged as read-only object with -freeze.
+package frozen
+
+type Point struct {
+	X, Y int
+}
+
+func Origin() Point { return Point{X: 1, Y: 2} }
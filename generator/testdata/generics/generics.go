@@ -0,0 +1,23 @@
+// Package generics declares a generic type with methods and a generic function,
+// which are skipped with a warning while NewStack is bridged.
+package generics
+
+type Stack[T any] struct {
+	items []T
+}
+
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
+func NewStack() *Stack[int] {
+	return &Stack[int]{}
+}
+
+func Identity[T any](v T) T {
+	return v
+}
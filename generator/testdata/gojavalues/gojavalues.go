@@ -0,0 +1,9 @@
+// Package gojavalues declares goja-aware functions taking and returning goja values, which
+// only build in a module requiring goja.
+package gojavalues
+
+import "github.com/dop251/goja"
+
+func Eval(v goja.Value) goja.Value { return v }
+
+func Keys(o *goja.Object) []string { return o.Keys() }
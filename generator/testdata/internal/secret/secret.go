@@ -0,0 +1,5 @@
+// Package secret is an internal package, which is only bridged with -allow-internal
+// into a directory allowed to import it.
+package secret
+
+func Reveal() string { return "secret" }
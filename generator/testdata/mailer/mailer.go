@@ -0,0 +1,8 @@
+// Package mailer declares functions with side effects, which the host tests against a stub bridge.
+package mailer
+
+import "errors"
+
+func Send(to string, body string) error { return errors.New("no mail server") }
+
+func Pending() int { return 0 }
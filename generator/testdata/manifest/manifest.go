@@ -0,0 +1,10 @@
+// Package manifest declares four functions of which a manifest allows two.
+package manifest
+
+func Alpha() int { return 1 }
+
+func Beta() int { return 2 }
+
+func Gamma() int { return 3 }
+
+func Delta() int { return 4 }
@@ -0,0 +1,6 @@
+// Package maps declares functions returning maps with string and non-string keys.
+package maps
+
+func Counts() map[string]int { return map[string]int{"c": 3, "a": 1, "b": 2} }
+
+func Names() map[int]string { return map[int]string{10: "ten", 2: "two", 1: "one"} }
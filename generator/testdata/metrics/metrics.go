@@ -0,0 +1,8 @@
+// Package metrics declares functions whose calls are observed by a metrics hook.
+package metrics
+
+import "time"
+
+func Add(a, b int) int { return a + b }
+
+func Wait(ms int) { time.Sleep(time.Duration(ms) * time.Millisecond) }
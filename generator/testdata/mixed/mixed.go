@@ -0,0 +1,23 @@
+// Package mixed declares bridged and skipped functions, structs, consts and vars.
+package mixed
+
+import "unsafe"
+
+const (
+	Pi    = 3.14
+	Label = "mixed"
+)
+
+var Count = 1
+
+type Circle struct {
+	Radius float64
+}
+
+func NewCircle(radius float64) *Circle { return &Circle{Radius: radius} }
+
+func (c *Circle) Area() float64 { return Pi * c.Radius * c.Radius }
+
+func Area(radius float64) float64 { return Pi * radius * radius }
+
+func Address(p unsafe.Pointer) uintptr { return uintptr(p) }
@@ -0,0 +1,9 @@
+// Package multifile declares functions using types of another file, of which Config is
+// missing and handle is unexported.
+package multifile
+
+func Open() *Handle { return &Handle{} }
+
+func Load() *Config { return nil }
+
+func Peek() []handle { return nil }
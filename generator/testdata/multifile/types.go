@@ -0,0 +1,5 @@
+package multifile
+
+type Handle struct{}
+
+type handle struct{}
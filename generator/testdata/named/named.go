@@ -0,0 +1,29 @@
+// Package named declares parameters of named map and slice types.
+package named
+
+import (
+	"sort"
+	"strings"
+)
+
+type Header map[string][]string
+
+func Format(h Header) string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	lines := []string{}
+	for _, key := range keys {
+		lines = append(lines, key+": "+strings.Join(h[key], ","))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type Tags []string
+
+func Count(tags Tags) int { return len(tags) }
@@ -0,0 +1,13 @@
+// Package nested declares parameters whose package is only used behind several slice,
+// array and pointer markers.
+package nested
+
+import "net/url"
+
+func F(x []*url.URL) int { return len(x) }
+
+func G(x [][]url.Values) int { return len(x) }
+
+func H(x **url.URL) bool { return x == nil }
+
+func I(x [2]*url.Userinfo) bool { return x[0] == nil }
@@ -0,0 +1,14 @@
+// Package nilable declares functions distinguishing nil from empty values.
+package nilable
+
+func IsNil(s []int) bool { return s == nil }
+
+func IsNilMap(m map[string]int) bool { return m == nil }
+
+func IsNilPointer(p *int) bool { return p == nil }
+
+func IsNilInterface(v interface{}) bool { return v == nil }
+
+func Nil() []int { return nil }
+
+func NilMap() map[string]int { return nil }
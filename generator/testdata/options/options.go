@@ -0,0 +1,36 @@
+// Package options declares a constructor taking functional options, which
+// -options bridges as a JS object like {name: "server", port: 8080}.
+package options
+
+type config struct {
+	Name string
+	Port int
+}
+
+type Option func(*config)
+
+func WithName(name string) Option {
+	return func(c *config) {
+		c.Name = name
+	}
+}
+
+func WithPort(port int) Option {
+	return func(c *config) {
+		c.Port = port
+	}
+}
+
+type Server struct {
+	Name string
+	Port int
+}
+
+func NewServer(opts ...Option) *Server {
+	c := &config{Port: 80}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &Server{Name: c.Name, Port: c.Port}
+}
@@ -0,0 +1,3 @@
+package platform
+
+func Platform() string { return "darwin" }
@@ -0,0 +1,5 @@
+// Package platform declares Platform in a file per GOOS, of which the one
+// matching the build context is bridged.
+package platform
+
+func Platform() string { return "linux" }
@@ -0,0 +1,6 @@
+// Package app uses the package model in its signatures, which -recursive bridges as well.
+package app
+
+import "github.com/mpetavy/goja_go/generator/testdata/recursive/model"
+
+func Load(id int) model.Record { return model.Record{ID: id} }
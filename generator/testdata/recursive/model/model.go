@@ -0,0 +1,8 @@
+// Package model declares the types used by the package app.
+package model
+
+type Record struct {
+	ID int
+}
+
+func Valid(r Record) bool { return r.ID > 0 }
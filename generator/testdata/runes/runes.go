@@ -0,0 +1,8 @@
+// Package runes declares functions taking and returning runes and bytes.
+package runes
+
+import "unicode"
+
+func ToUpper(r rune) rune { return unicode.ToUpper(r) }
+
+func Next(b byte) byte { return b + 1 }
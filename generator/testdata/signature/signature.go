@@ -0,0 +1,6 @@
+// Package signature declares a signature check taking a fixed-size and a variable byte slice.
+package signature
+
+func Verify(sig [64]byte, msg []byte) bool { return sig[0] == 0xab && sig[63] == 0xcd && string(msg) == "msg" }
+
+func Sign(msg []byte) [4]byte { return [4]byte{0xde, 0xad, 0xbe, 0xef} }
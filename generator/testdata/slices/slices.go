@@ -0,0 +1,14 @@
+// Package slices declares functions retaining and returning the same slice.
+package slices
+
+var kept []int
+
+func Keep(s []int) { kept = s }
+
+func Kept() []int { return kept }
+
+func Double() {
+	for i := range kept {
+		kept[i] *= 2
+	}
+}
@@ -0,0 +1,9 @@
+// Package typecheck imports a package whose name differs from the last element
+// of its import path, which only the type check resolves.
+package typecheck
+
+import (
+	random "math/rand/v2"
+)
+
+func Roll(n int) int { return random.IntN(n) + 1 }
@@ -0,0 +1,6 @@
+// Package unmarshal declares a function writing back through a pointer to an interface.
+package unmarshal
+
+import "encoding/json"
+
+func Unmarshal(data []byte, v *interface{}) error { return json.Unmarshal(data, v) }
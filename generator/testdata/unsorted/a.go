@@ -0,0 +1,3 @@
+package unsorted
+
+func Mid() int { return 13 }
@@ -0,0 +1,5 @@
+package unsorted
+
+import "time"
+
+func Beta(d time.Duration) int { return 2 }
@@ -0,0 +1,6 @@
+// Package unsorted declares its functions unordered across several files.
+package unsorted
+
+func Zeta() int { return 26 }
+
+func Alpha() int { return 1 }
@@ -0,0 +1,33 @@
+// Package waiters declares an interface JS objects can implement next to one whose method
+// returns a channel of an anonymous struct type.
+package waiters
+
+type Handler interface {
+	Handle(name string) string
+}
+
+type Doner interface {
+	Done() <-chan struct{}
+}
+
+type closed struct{}
+
+func (closed) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+
+	return ch
+}
+
+func Call(h Handler, name string) string { return h.Handle(name) }
+
+func NewDoner() Doner { return closed{} }
+
+func Closed(d Doner) bool {
+	select {
+	case <-d.Done():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (data *Data) testFilename() string {
+	return strings.TrimSuffix(data.Filename, ".go") + "_test.go"
+}
+
+// jsZero returns a JS value of the TS parameter type typ converting to the zero value of the Go
+// parameter, which the generated test calls the function with
+func (data *Data) jsZero(typ string) string {
+	if strings.HasPrefix(typ, "(") && strings.Contains(typ, "=>") {
+		return "function() {}"
+	}
+
+	// object literals are structs or the nullable interfaces implemented by JS objects
+	if strings.HasPrefix(typ, "{") {
+		if strings.HasSuffix(typ, " | null") {
+			return "null"
+		}
+
+		return "{}"
+	}
+
+	first, _, _ := strings.Cut(typ, " | ")
+
+	switch {
+	case strings.HasSuffix(first, "[]") || strings.HasPrefix(first, "Array<") || strings.HasPrefix(first, "ReadonlyArray<"):
+		return "[]"
+	case first == "number" || first == "bigint":
+		return "0"
+	case first == "string":
+		return `""`
+	case first == "boolean":
+		return "false"
+	case first == "Date":
+		return "new Date(0)"
+	case first == "ArrayBuffer":
+		return "new ArrayBuffer(0)"
+	case first == "Uint8Array":
+		return "new Uint8Array(0)"
+	case strings.HasPrefix(first, "Map<"):
+		return "new Map()"
+	case strings.HasPrefix(first, "Record<") || strings.HasPrefix(first, "Readonly<"):
+		return "{}"
+	}
+
+	if name, ok := strings.CutPrefix(first, data.JsStructName+"."); ok && data.isBridgedType(name) {
+		return "new " + first + "()"
+	}
+
+	return "null"
+}
+
+// testArg returns the JS argument the generated test passes for the parameter p
+func (data *Data) testArg(p Param) string {
+	return data.jsZeroOf(p.Expr, data.tsParam(p))
+}
+
+// jsZeroOf returns jsZero of the TS type typ of expr except for fixed-length arrays, which convert
+// only from arrays or hex strings of their length
+func (data *Data) jsZeroOf(expr ast.Expr, typ string) string {
+	array, ok := expr.(*ast.ArrayType)
+	if !ok || array.Len == nil {
+		return data.jsZero(typ)
+	}
+
+	n, ok := data.arrayLen(array.Len)
+	if !ok {
+		return data.jsZero(typ)
+	}
+
+	if typ == "string" {
+		return `"` + strings.Repeat("00", n) + `"`
+	}
+
+	if !strings.HasSuffix(typ, "[]") && !strings.HasPrefix(typ, "Array<") {
+		return data.jsZero(typ)
+	}
+
+	elem := data.jsZeroOf(array.Elt, data.tsType(array.Elt))
+
+	return "[" + strings.Join(slices.Repeat([]string{elem}, n), ", ") + "]"
+}
+
+// arrayLen evaluates the length of an array type given by a literal or a constant
+func (data *Data) arrayLen(expr ast.Expr) (int, bool) {
+	var ident *ast.Ident
+
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.INT {
+			return 0, false
+		}
+
+		n, err := strconv.ParseInt(x.Value, 0, 64)
+
+		return int(n), err == nil
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	default:
+		return 0, false
+	}
+
+	if data.info == nil {
+		return 0, false
+	}
+
+	c, ok := data.info.Uses[ident].(*types.Const)
+	if !ok {
+		return 0, false
+	}
+
+	n, ok := constant.Int64Val(constant.ToInt(c.Val()))
+
+	return int(n), ok
+}
@@ -0,0 +1,29 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTestArgsOfArrays(t *testing.T) {
+	generateTestdata(t, "arrays", "-tests")
+
+	ba, err := os.ReadFile(strings.TrimSuffix(bridgeFilename("arrays"), ".go") + "_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, string(ba), "`hash([0, 0, 0, 0])`", "`digest(["+strings.Repeat("0, ", 31)+"0])`", "`grid([[0, 0], [0, 0]])`")
+}
+
+func TestTestArgsOfHexArrays(t *testing.T) {
+	generateTestdata(t, "arrays", "-tests", "-bytes-as-hex")
+
+	ba, err := os.ReadFile(strings.TrimSuffix(bridgeFilename("arrays"), ".go") + "_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, string(ba), "`hash(\"00000000\")`")
+}
@@ -0,0 +1,424 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+var tsReserved = []string{
+	"arguments", "break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do",
+	"else", "enum", "eval", "export", "extends", "false", "finally", "for", "function", "if", "implements",
+	"import", "in", "instanceof", "interface", "let", "new", "null", "package", "private", "protected",
+	"public", "return", "static", "super", "switch", "this", "throw", "true", "try", "typeof", "var", "void",
+	"while", "with", "yield",
+}
+
+func tsName(name string) string {
+	if slices.Contains(tsReserved, name) {
+		return name + "_"
+	}
+
+	return name
+}
+
+func tsNullable(typ string) string {
+	if typ == "any" || strings.HasSuffix(typ, " | null") {
+		return typ
+	}
+
+	return typ + " | null"
+}
+
+func tsArray(typ string) string {
+	if strings.Contains(typ, " ") {
+		return "(" + typ + ")[]"
+	}
+
+	return typ + "[]"
+}
+
+func tsKey(typ string) string {
+	if typ == "number" {
+		return typ
+	}
+
+	return "string"
+}
+
+// tsStream maps an io interface to the JS stream-like object created by bridgeStream
+func tsStream(name string) string {
+	methods := []string{}
+	if strings.Contains(name, "Read") {
+		methods = append(methods, "read(size?: number): ArrayBuffer | null;")
+	}
+
+	if strings.Contains(name, "Write") {
+		methods = append(methods, "write(data: ArrayBuffer | string | number[]): number;")
+	}
+
+	if strings.Contains(name, "Close") {
+		methods = append(methods, "close(): void;")
+	}
+
+	return "{ " + strings.Join(methods, " ") + " }"
+}
+
+// tsTypeName maps the Go type typ to its TS type for templates. It only resolves the bridged types
+// of the declarations of the package, as templates are rendered from cached scans as well.
+func (data *Data) tsTypeName(typ string) string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return "any"
+	}
+
+	var tsOf func(expr ast.Expr) string
+	tsOf = func(expr ast.Expr) string {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			switch t.Name {
+			case "string":
+				return "string"
+			case "bool":
+				return "boolean"
+			case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+				"float32", "float64", "byte", "rune":
+				return "number"
+			}
+
+			for _, bridged := range data.Types {
+				if bridged.Name == t.Name {
+					return data.JsStructName + "." + t.Name
+				}
+			}
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Duration" {
+				return "number"
+			}
+
+			if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" && *timeAsDate {
+				return "Date"
+			}
+		case *ast.StarExpr:
+			return tsNullable(tsOf(t.X))
+		case *ast.Ellipsis:
+			return tsArray(tsOf(t.Elt))
+		case *ast.ArrayType:
+			return tsArray(tsOf(t.Elt))
+		case *ast.MapType:
+			return fmt.Sprintf("Record<%s, %s>", tsKey(tsOf(t.Key)), tsOf(t.Value))
+		}
+
+		return "any"
+	}
+
+	return tsOf(expr)
+}
+
+func (data *Data) tsType(expr ast.Expr) string {
+	return data.tsTypeOf(expr, nil)
+}
+
+func (data *Data) tsTypeOf(expr ast.Expr, seen []string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if arg, ok := data.typeArgs[t.Name]; ok {
+			return data.tsTypeOf(arg, seen)
+		}
+
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "byte", "rune":
+			return "number"
+		}
+
+		if data.isBridgedType(t.Name) {
+			return data.JsStructName + "." + t.Name
+		}
+
+		spec, ok := data.types[t.Name]
+		if !ok || spec.TypeParams != nil || slices.Contains(seen, t.Name) {
+			return "any"
+		}
+
+		return data.tsTypeOf(spec.Type, append(seen, t.Name))
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Duration" {
+			return "number"
+		}
+
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" && *timeAsDate {
+			return "Date"
+		}
+
+		if name := data.stream(t); name != "" {
+			return tsStream(name)
+		}
+	case *ast.StarExpr:
+		return tsNullable(data.tsTypeOf(t.X, seen))
+	case *ast.Ellipsis:
+		return tsArray(data.tsTypeOf(t.Elt, seen))
+	case *ast.ArrayType:
+		return tsArray(data.tsTypeOf(t.Elt, seen))
+	case *ast.MapType:
+		return fmt.Sprintf("Record<%s, %s>", tsKey(data.tsTypeOf(t.Key, seen)), data.tsTypeOf(t.Value, seen))
+	case *ast.StructType:
+		fields := []string{}
+		for _, field := range t.Fields.List {
+			for _, name := range field.Names {
+				if name.IsExported() {
+					fields = append(fields, fmt.Sprintf("%s: %s;", name.Name, data.tsTypeOf(field.Type, seen)))
+				}
+			}
+		}
+
+		if len(fields) == 0 {
+			return "{}"
+		}
+
+		return "{ " + strings.Join(fields, " ") + " }"
+	case *ast.FuncType:
+		params := []string{}
+		for i, p := range data.formatParams(t.Params) {
+			if p.Variadic {
+				params = append(params, fmt.Sprintf("...p%d: %s", i, tsArray(data.tsTypeOf(p.elem().Expr, seen))))
+			} else {
+				params = append(params, fmt.Sprintf("p%d: %s", i, data.tsTypeOf(p.Expr, seen)))
+			}
+		}
+
+		return fmt.Sprintf("((%s) => %s)", strings.Join(params, ", "), data.tsReturn(data.formatResults(t.Results), seen))
+	}
+
+	return "any"
+}
+
+// tsImplementation maps an implementable interface to the JS object implementing it
+func (data *Data) tsImplementation(name string) string {
+	methods := []string{}
+	for _, method := range data.types[name].Type.(*ast.InterfaceType).Methods.List {
+		fn := method.Type.(*ast.FuncType)
+
+		params := []string{}
+		for i, p := range data.formatParams(fn.Params) {
+			params = append(params, fmt.Sprintf("p%d: %s", i, data.tsType(p.Expr)))
+		}
+
+		methods = append(methods, fmt.Sprintf("%s(%s): %s;", jsName(method.Names[0].Name), strings.Join(params, ", "), data.tsReturn(data.formatResults(fn.Results), nil)))
+	}
+
+	return "{ " + strings.Join(methods, " ") + " }"
+}
+
+// tsReturn maps results to the JS return value, which drops a trailing error thrown as exception
+// and collects multiple results into an array
+func (data *Data) tsReturn(results []Param, seen []string) string {
+	if len(results) > 0 && results[len(results)-1].Type == "error" {
+		results = results[:len(results)-1]
+	}
+
+	types := []string{}
+	for _, r := range results {
+		types = append(types, data.tsTypeOf(r.Expr, seen))
+	}
+
+	switch len(types) {
+	case 0:
+		return "void"
+	case 1:
+		return types[0]
+	default:
+		return "[" + strings.Join(types, ", ") + "]"
+	}
+}
+
+// tsParam maps a parameter to the TS type accepted by its conversion in convertParam
+func (data *Data) tsParam(p Param) string {
+	switch {
+	case data.isGojaType(p.Expr):
+		return "any"
+	case *options && data.isOptions(p.Expr):
+		return "Record<string, any>"
+	case *arrayBuffer != "" && isBytes(p.Expr) && p.Expr.(*ast.ArrayType).Len == nil:
+		return "ArrayBuffer | Uint8Array | number[] | string | null"
+	case *bytesAsHex && isBytes(p.Expr):
+		return "string"
+	case *duration != "" && p.Type == "time.Duration":
+		return "number | string"
+	case *int64Mode != "number" && (p.Type == "int64" || p.Type == "uint64"):
+		return "number | bigint | string"
+	case *timeAsDate && p.Type == "time.Time":
+		return "Date | number | string | null"
+	case *runes && (p.Type == "rune" || p.Type == "byte"):
+		return "string | number"
+	case data.stream(p.Expr) == "Reader":
+		return "string | ArrayBuffer | { read(size: number): ArrayBuffer | string | number[] | null } | null"
+	case data.stream(p.Expr) == "Writer":
+		return "{ write(data: ArrayBuffer): number | void } | null"
+	case data.stream(p.Expr) != "":
+		return tsNullable(data.tsType(p.Expr))
+	}
+
+	if star, ok := p.Expr.(*ast.StarExpr); ok && data.isInterface(star.X) {
+		return "object"
+	}
+
+	if ident, ok := p.Expr.(*ast.Ident); ok && data.isImplementable(ident.Name) {
+		return tsNullable(data.tsImplementation(ident.Name))
+	}
+
+	typ := data.tsType(p.Expr)
+	if data.isNilable(p.Expr) {
+		return tsNullable(typ)
+	}
+
+	return typ
+}
+
+// tsResult maps a result to the TS type returned by its conversion in convertResult
+func (data *Data) tsResult(r Param) string {
+	switch {
+	case data.isGojaType(r.Expr):
+		return "any"
+	case (*duration == "string" || *duration == "iso") && r.Type == "time.Duration":
+		return "string"
+	case *int64Mode != "number" && (r.Type == "int64" || r.Type == "uint64"):
+		return *int64Mode
+	case *timeAsDate && r.Type == "time.Time":
+		return "Date | null"
+	case *runes && r.Type == "rune":
+		return "string"
+	case *mapReturn != "" && data.isMap(r.Expr):
+		m := data.underlyingComposite(r.Expr)
+		if m == nil {
+			m = r.Expr
+		}
+
+		key, value := data.tsType(m.(*ast.MapType).Key), data.tsType(m.(*ast.MapType).Value)
+		if *mapReturn == "map" {
+			return fmt.Sprintf("Map<%s, %s> | null", key, value)
+		}
+
+		return fmt.Sprintf("Record<%s, %s> | null", tsKey(key), value)
+	case data.isChan(r.Expr):
+		return "any"
+	case data.stream(r.Expr) != "":
+		return tsNullable(data.tsType(r.Expr))
+	case *freeze && data.isStruct(r.Expr):
+		if _, _, ok := data.bridgedType(r.Expr); !ok {
+			return fmt.Sprintf("Readonly<%s>", data.tsType(r.Expr))
+		}
+	case *arrayBuffer != "" && isBytes(r.Expr) && r.Expr.(*ast.ArrayType).Len == nil:
+		return "ArrayBuffer | null"
+	case *bytesAsHex && isBytes(r.Expr):
+		if r.Expr.(*ast.ArrayType).Len == nil {
+			return "string | null"
+		}
+
+		return "string"
+	}
+
+	typ := data.tsType(r.Expr)
+	if data.isNilable(r.Expr) {
+		return tsNullable(typ)
+	}
+
+	return typ
+}
+
+func (data *Data) tsResults(results []Param) string {
+	if *commaOk && len(results) == 2 && results[1].Type == "bool" {
+		return data.tsResult(results[0]) + " | undefined"
+	}
+
+	tuple := false
+	if len(results) > 0 && results[len(results)-1].Type == "error" {
+		results = results[:len(results)-1]
+		tuple = *errorMode == "tuple"
+	}
+
+	types := []string{}
+	for _, r := range results {
+		types = append(types, data.tsResult(r))
+	}
+
+	if *multiReturn == "object" && len(types) > 1 {
+		fields := []string{}
+		for i, r := range results {
+			fields = append(fields, fmt.Sprintf("%s: %s", r.Key, types[i]))
+		}
+
+		types = []string{"{ " + strings.Join(fields, "; ") + " }"}
+	}
+
+	if tuple {
+		return "[" + strings.Join(append(types, "Error | null"), ", ") + "]"
+	}
+
+	switch len(types) {
+	case 0:
+		return "void"
+	case 1:
+		return types[0]
+	default:
+		return "[" + strings.Join(types, ", ") + "]"
+	}
+}
+
+// tsValue maps a constant or variable declared by spec, following the implicit repetition
+// of the previous specification prev in constant groups
+func (data *Data) tsValue(spec *ast.ValueSpec, prev *ast.ValueSpec, index int) string {
+	if spec.Type == nil && len(spec.Values) == 0 && prev != nil {
+		spec = prev
+	}
+
+	if spec.Type != nil {
+		return data.tsType(spec.Type)
+	}
+
+	if index >= len(spec.Values) {
+		return "any"
+	}
+
+	switch v := spec.Values[index].(type) {
+	case *ast.BasicLit:
+		if v.Kind == token.STRING {
+			return "string"
+		}
+
+		return "number"
+	case *ast.CompositeLit:
+		return data.tsType(v.Type)
+	case *ast.UnaryExpr:
+		if v.Op == token.AND {
+			if lit, ok := v.X.(*ast.CompositeLit); ok {
+				return data.tsType(lit.Type)
+			}
+		}
+	case *ast.Ident:
+		if v.Name == "true" || v.Name == "false" {
+			return "boolean"
+		}
+
+		if v.Name == "iota" {
+			return "number"
+		}
+	case *ast.BinaryExpr:
+		if x, ok := v.X.(*ast.BasicLit); ok && x.Kind != token.STRING {
+			return "number"
+		}
+
+		if x, ok := v.X.(*ast.Ident); ok && x.Name == "iota" {
+			return "number"
+		}
+	}
+
+	return "any"
+}
@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxTypeErrors is the number of type errors reported of a package
+const maxTypeErrors = 3
+
+// typecheck type checks the files of the package in the directory dir against the export data
+// of its imports, so qualified identifiers resolve to their exact import paths instead of being
+// matched by suffix. The returned info is complete unless an error is returned.
+func typecheck(fset *token.FileSet, files map[string]*ast.File, pkgPath string, dir string) (*types.Info, error) {
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+
+	list := []*ast.File{}
+	for _, filename := range sortedKeys(files) {
+		list = append(list, files[filename])
+	}
+
+	exports, err := exportData(dir, fileImports(list))
+	if exports == nil {
+		return info, err
+	}
+
+	// the type errors caused by imports go list failed for follow the errors of go list
+	errs := []error{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			export, ok := exports[path]
+			if !ok || export == "" {
+				return nil, fmt.Errorf("no export data of %s", path)
+			}
+
+			return os.Open(export)
+		}),
+		FakeImportC: true,
+		Error: func(err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	_, _ = conf.Check(pkgPath, fset, list, info)
+
+	if len(errs) > maxTypeErrors {
+		errs = append(errs[:maxTypeErrors], fmt.Errorf("and %d more errors", len(errs)-maxTypeErrors))
+	}
+
+	return info, errors.Join(errs...)
+}
+
+// fileImports returns the sorted import paths of the files except the pseudo package C of cgo
+func fileImports(files []*ast.File) []string {
+	imports := []string{}
+
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err == nil && path != "C" && !slices.Contains(imports, path) {
+				imports = append(imports, path)
+			}
+		}
+	}
+
+	sort.Strings(imports)
+
+	return imports
+}
+
+// exportData returns the export data files of the imports by their import paths, which go list
+// compiles in the build context of -goos, -goarch and -tags as seen from the package directory dir.
+// The imports go list failed for are returned without export data along with their errors.
+func exportData(dir string, imports []string) (map[string]string, error) {
+	exports := make(map[string]string)
+	if len(imports) == 0 {
+		return exports, nil
+	}
+
+	ctx := buildContext()
+
+	args := []string{"list", "-e", "-export", "-json=ImportPath,Export,Error"}
+	if len(ctx.BuildTags) > 0 {
+		args = append(args, "-tags", strings.Join(ctx.BuildTags, ","))
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("go", append(args, imports...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS="+ctx.GOOS, "GOARCH="+ctx.GOARCH)
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the imports failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	errs := []error{}
+
+	decoder := json.NewDecoder(bytes.NewReader(stdout))
+	for decoder.More() {
+		pkg := struct {
+			ImportPath string
+			Export     string
+			Error      *struct {
+				Err string
+			}
+		}{}
+
+		err := decoder.Decode(&pkg)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.Error != nil {
+			errs = append(errs, fmt.Errorf("%s", pkg.Error.Err))
+		}
+
+		exports[pkg.ImportPath] = pkg.Export
+	}
+
+	return exports, errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parseTestFile(t *testing.T, filename string) (*token.FileSet, map[string]*ast.File) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fset, map[string]*ast.File{filename: file}
+}
+
+func TestTypecheck(t *testing.T) {
+	dir := filepath.Join("testdata", "typecheck")
+
+	fset, files := parseTestFile(t, filepath.Join(dir, "typecheck.go"))
+
+	info, err := typecheck(fset, files, "example.com/typecheck", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for ident, obj := range info.Uses {
+		if pkgName, ok := obj.(*types.PkgName); ok && ident.Name == "random" {
+			found = pkgName.Imported().Path() == "math/rand/v2"
+		}
+	}
+
+	if !found {
+		t.Errorf("random is not resolved to math/rand/v2")
+	}
+}
+
+func TestTypecheckErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "broken.go")
+
+	err := os.WriteFile(filename, []byte("package broken\n\nimport \"strings\"\n\nfunc Upper(s string) string { return strings.ToUpperCase(s) }\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset, files := parseTestFile(t, filename)
+
+	_, err = typecheck(fset, files, "example.com/broken", dir)
+	if err == nil || !strings.Contains(err.Error(), "ToUpperCase") {
+		t.Errorf("expected the undefined strings.ToUpperCase to be reported, got %v", err)
+	}
+}
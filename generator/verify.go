@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/mpetavy/common"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// verifyExcluded lists the functions and methods excluded by -verify exclude, which failed
+// to compile in an earlier generation of their package, keyed by verifyKey
+var verifyExcluded = make(map[string]bool)
+
+// verifyKey returns the key of the function or method name of the package pkgPath in verifyExcluded
+func verifyKey(pkgPath string, name string) string {
+	return pkgPath + " " + name
+}
+
+// excludedByVerify reports whether the function or method name failed to compile in an earlier
+// generation of the package
+func (data *Data) excludedByVerify(name string) bool {
+	return verifyExcluded[verifyKey(data.pkgPath, name)]
+}
+
+type symbolRange struct {
+	name  string
+	start int
+	end   int
+}
+
+// verifyPackage builds the generated package of data and, with -verify exclude, regenerates
+// it from the package spec, which may include its @version, without the bridged functions
+// failing to compile until it does
+func verifyPackage(spec string, data *Data) (*Data, error) {
+	for {
+		failed, err := buildPackage(data)
+		if common.Error(err) {
+			return nil, err
+		}
+
+		if len(failed) == 0 {
+			return data, nil
+		}
+
+		if *verify == "report" {
+			return nil, fmt.Errorf("generated package %s does not compile:\n%s", data.OutputPkg, strings.Join(failed, "\n"))
+		}
+
+		names := []string{}
+		for _, line := range failed {
+			name, _, _ := strings.Cut(line, ":")
+			if data.excludedByVerify(name) {
+				return nil, fmt.Errorf("generated package %s does not compile:\n%s", data.OutputPkg, strings.Join(failed, "\n"))
+			}
+
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		for _, name := range names {
+			verifyExcluded[verifyKey(data.pkgPath, name)] = true
+		}
+
+		data, err = generate(spec)
+		if common.Error(err) {
+			return nil, err
+		}
+	}
+}
+
+// buildPackage builds the generated package of data and returns the compile errors, each
+// prefixed by the bridged function or method it occurred in
+func buildPackage(data *Data) ([]string, error) {
+	cmd := exec.Command("go", "build", "-gcflags=-e", ".")
+	cmd.Dir = filepath.Dir(data.Filename)
+
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	ranges, rangesErr := data.symbolRanges()
+	if common.Error(rangesErr) {
+		return nil, rangesErr
+	}
+
+	re := regexp.MustCompile(`^(.+\.go):(\d+):\d+: (.*)$`)
+
+	failed := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		match := re.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || filepath.Base(match[1]) != filepath.Base(data.Filename) {
+			continue
+		}
+
+		n, _ := strconv.Atoi(match[2])
+
+		name := ""
+		for _, r := range ranges {
+			if n >= r.start && n <= r.end {
+				name = r.name
+			}
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("generated package %s does not compile at line %d outside of bridged functions: %s", data.OutputPkg, n, match[3])
+		}
+
+		if msg := fmt.Sprintf("%s: %s", name, match[3]); !slices.Contains(failed, msg) {
+			failed = append(failed, msg)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil, fmt.Errorf("generated package %s does not compile: %s", data.OutputPkg, strings.TrimSpace(string(output)))
+	}
+
+	return failed, nil
+}
+
+// symbolRanges returns the lines of the generated file spanned by the wrappers of the bridged
+// functions and methods, with the method closures nested in the ranges of their type wrappers
+func (data *Data) symbolRanges() ([]symbolRange, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, data.Filename, nil, 0)
+	if common.Error(err) {
+		return nil, err
+	}
+
+	ranges := []symbolRange{}
+	add := func(name string, node ast.Node) {
+		ranges = append(ranges, symbolRange{
+			name:  name,
+			start: fset.Position(node.Pos()).Line,
+			end:   fset.Position(node.End()).Line,
+		})
+	}
+
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv == nil {
+			continue
+		}
+
+		if data.indexFunc(fd.Name.Name) != -1 {
+			add(fd.Name.Name, fd)
+
+			continue
+		}
+
+		if name, ok := strings.CutSuffix(fd.Name.Name, "Async"); ok && data.indexFunc(name) != -1 {
+			add(name, fd)
+
+			continue
+		}
+
+		for _, typ := range data.Types {
+			if fd.Name.Name != "wrap"+typ.Name {
+				continue
+			}
+
+			ast.Inspect(fd.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if ok && len(call.Args) == 4 {
+					data.propertyRanges(typ, call, add)
+				}
+
+				if !ok || len(call.Args) != 2 {
+					return true
+				}
+
+				lit, ok := call.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+
+				jsName, _ := strconv.Unquote(lit.Value)
+
+				for _, m := range typ.Methods {
+					if m.JsName == jsName {
+						add(typ.Name+"."+m.Name, call)
+					}
+				}
+
+				return true
+			})
+		}
+	}
+
+	return ranges, nil
+}
+
+// propertyRanges adds the ranges of the getter and setter wrappers defining a property of typ
+func (data *Data) propertyRanges(typ Type, call *ast.CallExpr, add func(name string, node ast.Node)) {
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+
+	jsName, _ := strconv.Unquote(lit.Value)
+
+	for _, p := range typ.Properties {
+		if p.JsName == jsName {
+			add(typ.Name+"."+p.Get.Name, call.Args[1])
+			add(typ.Name+"."+p.Set.Name, call.Args[2])
+		}
+	}
+}
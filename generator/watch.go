@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/mpetavy/common"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const watchInterval = time.Second
+
+// watchedDirs are the directories of the packages generated by the last run
+var watchedDirs = make(map[string]bool)
+
+// fingerprint summarizes the modification times and sizes of the Go files of the watched
+// directories, of the template files and of the files given by flags, so any change of them changes it
+func fingerprint() string {
+	files := append([]string{*configFile, *headerFile, *manifest}, templateFiles()...)
+
+	for dir := range watchedDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err == nil && filter(info) {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(files)
+
+	sb := strings.Builder{}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s -\n", file)
+
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s %d %d\n", file, info.ModTime().UnixNano(), info.Size())
+	}
+
+	return sb.String()
+}
+
+// watchSources regenerates by generate whenever the fingerprint changes, reporting failures
+// without stopping to watch
+func watchSources(generate func() error) error {
+	last := fingerprint()
+
+	common.Info("watching for changes")
+
+	for {
+		time.Sleep(watchInterval)
+
+		current := fingerprint()
+		if current == last {
+			continue
+		}
+
+		last = current
+
+		common.Info("change detected, regenerating")
+
+		err := generate()
+		common.Error(err)
+
+		// packages may have been added by the config file
+		last = fingerprint()
+	}
+}
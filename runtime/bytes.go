@@ -0,0 +1,244 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+func Bytes(vm *goja.Runtime, v goja.Value) []byte {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	}
+
+	switch x := v.Export().(type) {
+	case string:
+		return []byte(x)
+	case goja.ArrayBuffer:
+		return x.Bytes()
+	}
+
+	var ba []byte
+
+	err := vm.ExportTo(v, &ba)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	return ba
+}
+
+func ArrayBuffer(vm *goja.Runtime, ba []byte) goja.Value {
+	if ba == nil {
+		return goja.Null()
+	}
+
+	return vm.ToValue(vm.NewArrayBuffer(ba))
+}
+
+func DecodeHex(vm *goja.Runtime, v goja.Value, size int) []byte {
+	var ba []byte
+
+	if v != nil && !goja.IsUndefined(v) && !goja.IsNull(v) {
+		var err error
+
+		ba, err = hex.DecodeString(v.String())
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	if size >= 0 && len(ba) != size {
+		panic(vm.NewGoError(fmt.Errorf("invalid hex length: expected %d bytes, got %d", size, len(ba))))
+	}
+
+	return ba
+}
+
+func EncodeHex(vm *goja.Runtime, ba []byte) goja.Value {
+	if ba == nil {
+		return goja.Null()
+	}
+
+	return vm.ToValue(hex.EncodeToString(ba))
+}
+
+type jsReader struct {
+	vm      *goja.Runtime
+	obj     *goja.Object
+	read    goja.Callable
+	pending []byte
+}
+
+func (r *jsReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		v, err := r.read(r.obj, r.vm.ToValue(len(p)))
+		if err != nil {
+			return 0, err
+		}
+
+		r.pending = Bytes(r.vm, v)
+		if len(r.pending) == 0 {
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+type jsWriter struct {
+	vm    *goja.Runtime
+	obj   *goja.Object
+	write goja.Callable
+}
+
+func (w *jsWriter) Write(p []byte) (int, error) {
+	v, err := w.write(w.obj, w.vm.ToValue(w.vm.NewArrayBuffer(bytes.Clone(p))))
+	if err != nil {
+		return 0, err
+	}
+
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return len(p), nil
+	}
+
+	return int(v.ToInteger()), nil
+}
+
+func streamValue(v goja.Value) (interface{}, bool) {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return nil, false
+	}
+
+	inner := obj.Get("__value")
+	if inner == nil {
+		return nil, false
+	}
+
+	return inner.Export(), true
+}
+
+func Reader(vm *goja.Runtime, v goja.Value) io.Reader {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	}
+
+	if inner, ok := streamValue(v); ok {
+		if r, ok := inner.(io.Reader); ok {
+			return r
+		}
+	}
+
+	switch x := v.Export().(type) {
+	case string:
+		return strings.NewReader(x)
+	case goja.ArrayBuffer:
+		return bytes.NewReader(x.Bytes())
+	}
+
+	if obj, ok := v.(*goja.Object); ok {
+		if read, ok := goja.AssertFunction(obj.Get("read")); ok {
+			return &jsReader{vm: vm, obj: obj, read: read}
+		}
+	}
+
+	panic(vm.NewTypeError("value is not readable"))
+}
+
+func Writer(vm *goja.Runtime, v goja.Value) io.Writer {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	}
+
+	if inner, ok := streamValue(v); ok {
+		if w, ok := inner.(io.Writer); ok {
+			return w
+		}
+	}
+
+	if obj, ok := v.(*goja.Object); ok {
+		if write, ok := goja.AssertFunction(obj.Get("write")); ok {
+			return &jsWriter{vm: vm, obj: obj, write: write}
+		}
+	}
+
+	panic(vm.NewTypeError("value is not writable"))
+}
+
+func Stream(vm *goja.Runtime, v interface{}) goja.Value {
+	if v == nil {
+		return goja.Null()
+	}
+
+	obj := vm.NewObject()
+
+	err := obj.DefineDataProperty("__value", vm.ToValue(v), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	if r, ok := v.(io.Reader); ok {
+		err = obj.Set("read", func(size goja.Value) goja.Value {
+			n := 4096
+			if size != nil && !goja.IsUndefined(size) && size.ToInteger() > 0 {
+				n = int(size.ToInteger())
+			}
+
+			buf := make([]byte, n)
+
+			k, err := r.Read(buf)
+			if k > 0 {
+				return vm.ToValue(vm.NewArrayBuffer(buf[:k]))
+			}
+
+			if err == io.EOF {
+				return goja.Null()
+			}
+
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+
+			return vm.ToValue(vm.NewArrayBuffer(nil))
+		})
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	if w, ok := v.(io.Writer); ok {
+		err = obj.Set("write", func(data goja.Value) int {
+			n, err := w.Write(Bytes(vm, data))
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+
+			return n
+		})
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	if c, ok := v.(io.Closer); ok {
+		err = obj.Set("close", func() {
+			err := c.Close()
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+		})
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	return obj
+}
@@ -0,0 +1,334 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+func Chan(vm *goja.Runtime, ch interface{}) goja.Value {
+	rv := reflect.ValueOf(ch)
+	if rv.IsNil() {
+		return goja.Null()
+	}
+
+	obj := vm.NewObject()
+
+	err := obj.DefineDataProperty("__value", vm.ToValue(ch), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	dir := rv.Type().ChanDir()
+	closed := false
+
+	if dir&reflect.RecvDir != 0 {
+		err = obj.Set("next", func() goja.Value {
+			result := vm.NewObject()
+
+			var v reflect.Value
+			ok := false
+			if !closed {
+				v, ok = rv.Recv()
+			}
+
+			_ = result.Set("done", !ok)
+			if ok {
+				_ = result.Set("value", v.Interface())
+			} else {
+				_ = result.Set("value", goja.Undefined())
+			}
+
+			return result
+		})
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	if dir&reflect.SendDir != 0 {
+		err = obj.Set("push", func(v goja.Value) {
+			value := reflect.New(rv.Type().Elem())
+
+			err := vm.ExportTo(v, value.Interface())
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+
+			rv.Send(value.Elem())
+		})
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	err = obj.Set("close", func() {
+		if closed {
+			return
+		}
+
+		closed = true
+
+		if dir&reflect.SendDir != 0 {
+			rv.Close()
+		}
+	})
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	return obj
+}
+
+func Freeze(vm *goja.Runtime, v interface{}) goja.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return goja.Null()
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return vm.ToValue(v)
+	}
+
+	obj := vm.NewObject()
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := vm.ToValue(rv.Field(i).Interface())
+		getter := vm.ToValue(func(goja.FunctionCall) goja.Value {
+			return value
+		})
+
+		err := obj.DefineAccessorProperty(field.Name, getter, nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	freeze, ok := goja.AssertFunction(vm.Get("Object").ToObject(vm).Get("freeze"))
+	if !ok {
+		return obj
+	}
+
+	_, err := freeze(goja.Undefined(), obj)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	return obj
+}
+
+// Enum sets the property name of obj to a frozen object mapping the names of an enum to
+// their values and, if numeric, the values back to their names like TypeScript enums
+func Enum(vm *goja.Runtime, obj *goja.Object, name string, numeric bool, names []string, values []interface{}) error {
+	enum := vm.NewObject()
+
+	for i, name := range names {
+		err := enum.Set(name, values[i])
+		if err != nil {
+			return err
+		}
+
+		if numeric {
+			err = enum.Set(fmt.Sprint(values[i]), name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if freeze, ok := goja.AssertFunction(vm.Get("Object").ToObject(vm).Get("freeze")); ok {
+		_, err := freeze(goja.Undefined(), enum)
+		if err != nil {
+			return err
+		}
+	}
+
+	return obj.DefineDataProperty(name, enum, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE)
+}
+
+// Options appends a functional option to the slice pointed to by target which sets the fields of
+// the config named like the keys of the JS object v
+func Options(vm *goja.Runtime, v goja.Value, target interface{}) {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return
+	}
+
+	obj := v.ToObject(vm)
+	options := reflect.ValueOf(target).Elem()
+
+	option := reflect.MakeFunc(options.Type().Elem(), func(args []reflect.Value) []reflect.Value {
+		config := args[0].Elem()
+
+		for _, key := range obj.Keys() {
+			field := config.FieldByNameFunc(func(name string) bool {
+				return strings.EqualFold(name, key)
+			})
+			if !field.IsValid() || !field.CanSet() {
+				panic(vm.NewGoError(fmt.Errorf("unknown option: %s", key)))
+			}
+
+			value := reflect.New(field.Type())
+
+			err := vm.ExportTo(obj.Get(key), value.Interface())
+			if err != nil {
+				panic(vm.NewGoError(err))
+			}
+
+			field.Set(value.Elem())
+		}
+
+		return nil
+	})
+
+	options.Set(reflect.Append(options, option))
+}
+
+// Int64 converts a JS number, BigInt or decimal string to an int64
+func Int64(vm *goja.Runtime, v goja.Value) int64 {
+	switch x := v.Export().(type) {
+	case *big.Int:
+		if !x.IsInt64() {
+			panic(vm.NewGoError(fmt.Errorf("integer out of range: %s", x)))
+		}
+
+		return x.Int64()
+	case string:
+		n, err := strconv.ParseInt(x, 10, 64)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+
+		return n
+	}
+
+	return v.ToInteger()
+}
+
+// Uint64 converts a JS number, BigInt or decimal string to an uint64
+func Uint64(vm *goja.Runtime, v goja.Value) uint64 {
+	switch x := v.Export().(type) {
+	case *big.Int:
+		if !x.IsUint64() {
+			panic(vm.NewGoError(fmt.Errorf("integer out of range: %s", x)))
+		}
+
+		return x.Uint64()
+	case string:
+		n, err := strconv.ParseUint(x, 10, 64)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+
+		return n
+	}
+
+	f := v.ToFloat()
+	if f < 0 || f >= math.MaxUint64 {
+		panic(vm.NewGoError(fmt.Errorf("integer out of range: %v", f)))
+	}
+
+	return uint64(f)
+}
+
+func Rune(vm *goja.Runtime, v goja.Value, limit rune) rune {
+	r := rune(v.ToInteger())
+
+	if s, ok := v.Export().(string); ok {
+		rs := []rune(s)
+		if len(rs) != 1 {
+			panic(vm.NewGoError(fmt.Errorf("expected a single character, got %q", s)))
+		}
+
+		r = rs[0]
+	}
+
+	if r < 0 || r > limit {
+		panic(vm.NewGoError(fmt.Errorf("character out of range: %d", r)))
+	}
+
+	return r
+}
+
+func sortedKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		default:
+			return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+		}
+	})
+
+	return keys
+}
+
+// ToObject converts the map v to a plain JS object with its keys sorted
+func ToObject(vm *goja.Runtime, v interface{}) goja.Value {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return goja.Null()
+	}
+
+	obj := vm.NewObject()
+
+	for _, key := range sortedKeys(rv) {
+		err := obj.Set(fmt.Sprint(key.Interface()), rv.MapIndex(key).Interface())
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	return obj
+}
+
+// ToMap converts the map v to a JS Map with its keys sorted
+func ToMap(vm *goja.Runtime, v interface{}) goja.Value {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return goja.Null()
+	}
+
+	m, err := vm.New(vm.Get("Map"))
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	set, ok := goja.AssertFunction(m.Get("set"))
+	if !ok {
+		panic(vm.NewTypeError("Map.prototype.set is not a function"))
+	}
+
+	for _, key := range sortedKeys(rv) {
+		_, err := set(m, vm.ToValue(key.Interface()), vm.ToValue(rv.MapIndex(key).Interface()))
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+	}
+
+	return m
+}
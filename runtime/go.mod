@@ -0,0 +1,12 @@
+module github.com/mpetavy/goja_go/runtime
+
+go 1.23
+
+require github.com/dop251/goja v0.0.0-20230427124612-428fc442ff5f
+
+require (
+	github.com/dlclark/regexp2 v1.7.0 // indirect
+	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
+	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
@@ -0,0 +1,13 @@
+// Package fastcalls declares functions with parameters and results of basic types, whose
+// bridge generated with -fast-calls -tests benchmarks the reflective against the fast calls.
+package fastcalls
+
+import "strings"
+
+func Add(a int, b int) int { return a + b }
+
+func Scale(x float64, factor float64) float64 { return x * factor }
+
+func Repeat(s string, count int) string { return strings.Repeat(s, count%8) }
+
+func Even(n int) bool { return n%2 == 0 }
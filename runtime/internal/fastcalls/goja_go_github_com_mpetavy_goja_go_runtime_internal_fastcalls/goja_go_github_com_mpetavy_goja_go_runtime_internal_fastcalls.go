@@ -0,0 +1,169 @@
+// goja_go inputs: eca451f05cdf8039faa98c65f97dc0a1d1a9966c7916fe934ca0144f03f5ccf5
+
+package goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dop251/goja"
+	"github.com/mpetavy/goja_go/runtime/internal/fastcalls"
+	"runtime/debug"
+)
+
+var ErrNotRegistered = errors.New("module not registered")
+
+type Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls struct {
+	vm *goja.Runtime
+}
+
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) Add(a int, b int) int {
+
+	if bridge.vm == nil {
+		panic(fmt.Errorf("Add: %w", ErrNotRegistered))
+	}
+	defer bridgeRecover(bridge.vm)
+	return fastcalls.Add(a, b)
+
+}
+
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) Even(n int) bool {
+
+	if bridge.vm == nil {
+		panic(fmt.Errorf("Even: %w", ErrNotRegistered))
+	}
+	defer bridgeRecover(bridge.vm)
+	return fastcalls.Even(n)
+
+}
+
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) Repeat(s string, count int) string {
+
+	if bridge.vm == nil {
+		panic(fmt.Errorf("Repeat: %w", ErrNotRegistered))
+	}
+	defer bridgeRecover(bridge.vm)
+	return fastcalls.Repeat(s, count)
+
+}
+
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) Scale(x float64, factor float64) float64 {
+
+	if bridge.vm == nil {
+		panic(fmt.Errorf("Scale: %w", ErrNotRegistered))
+	}
+	defer bridgeRecover(bridge.vm)
+	return fastcalls.Scale(x, factor)
+
+}
+
+// fastAdd calls Add with the arguments converted without reflection
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) fastAdd(call goja.FunctionCall) goja.Value {
+	return bridge.vm.ToValue(bridge.Add(int(call.Argument(0).ToInteger()), int(call.Argument(1).ToInteger())))
+}
+
+// fastEven calls Even with the arguments converted without reflection
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) fastEven(call goja.FunctionCall) goja.Value {
+	return bridge.vm.ToValue(bridge.Even(int(call.Argument(0).ToInteger())))
+}
+
+// fastRepeat calls Repeat with the arguments converted without reflection
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) fastRepeat(call goja.FunctionCall) goja.Value {
+	return bridge.vm.ToValue(bridge.Repeat(bridgeArgString(call, 0), int(call.Argument(1).ToInteger())))
+}
+
+// fastScale calls Scale with the arguments converted without reflection
+func (bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) fastScale(call goja.FunctionCall) goja.Value {
+	return bridge.vm.ToValue(bridge.Scale(bridgeArgFloat(call, 0), bridgeArgFloat(call, 1)))
+}
+
+// bridgeArg returns the i-th argument of call, nil if it is missing like goja passes it by reflection
+func bridgeArg(call goja.FunctionCall, i int) goja.Value {
+	if i >= len(call.Arguments) {
+		return nil
+	}
+
+	return call.Arguments[i]
+}
+
+func bridgeArgString(call goja.FunctionCall, i int) string {
+	if i >= len(call.Arguments) {
+		return ""
+	}
+
+	return call.Arguments[i].String()
+}
+
+// bridgeArgFloat returns the i-th argument of call as float, 0 if it is missing instead of NaN for undefined
+func bridgeArgFloat(call goja.FunctionCall, i int) float64 {
+	if i >= len(call.Arguments) {
+		return 0
+	}
+
+	return call.Arguments[i].ToFloat()
+}
+
+// bridgeResult returns undefined for a nil result like goja does for results returned by reflection
+func bridgeResult(v goja.Value) goja.Value {
+	if v == nil {
+		return goja.Undefined()
+	}
+
+	return v
+}
+
+func bridgeRecover(vm *goja.Runtime) {
+	r := recover()
+
+	switch r.(type) {
+	case nil:
+		return
+	case *goja.Exception, *goja.InterruptedError, goja.Value:
+		panic(r)
+	}
+
+	panic(vm.NewGoError(fmt.Errorf("panic: %v\n%s", r, debug.Stack())))
+}
+
+func NewGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcallsObject(vm *goja.Runtime) (*goja.Object, error) {
+	s := &Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls{vm: vm}
+
+	var err error
+
+	obj := vm.NewObject()
+
+	err = obj.Set("add", s.fastAdd)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("even", s.fastEven)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("repeat", s.fastRepeat)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.Set("scale", s.fastScale)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func RegisterGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls(vm *goja.Runtime) error {
+	obj, err := NewGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcallsObject(vm)
+	if err != nil {
+		return err
+	}
+
+	err = vm.Set("goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls", obj)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// goja_go inputs: eca451f05cdf8039faa98c65f97dc0a1d1a9966c7916fe934ca0144f03f5ccf5
+
+package goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+)
+
+// TestGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls calls each bridged function with the zero values of its parameters,
+// failing on the TypeErrors of arguments or results which do not convert anymore
+func TestGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		bridgeTestCall(t, `add(0, 0)`)
+	})
+	t.Run("even", func(t *testing.T) {
+		bridgeTestCall(t, `even(0)`)
+	})
+	t.Run("repeat", func(t *testing.T) {
+		bridgeTestCall(t, `repeat("", 0)`)
+	})
+	t.Run("scale", func(t *testing.T) {
+		bridgeTestCall(t, `scale(0, 0)`)
+	})
+}
+
+// bridgeTestCall calls the function by call on a new runtime, exceptions other than TypeErrors and
+// panics are errors of the function with zero values and not of the bridge
+func bridgeTestCall(t *testing.T, call string) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("%s panics: %v", call, r)
+		}
+	}()
+
+	vm := goja.New()
+
+	err := RegisterGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls(vm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = vm.RunString("goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls." + call)
+	if ex, ok := err.(*goja.Exception); ok && ex.Value().ToObject(vm).Get("name").String() != "TypeError" {
+		t.Logf("%s throws: %v", call, ex)
+
+		return
+	}
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Add compares the call of Add by reflection with the one without
+func BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Add(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any { return bridge.Add }, `0, 0`)
+	})
+	b.Run("fast", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any { return bridge.fastAdd }, `0, 0`)
+	})
+}
+
+// BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Even compares the call of Even by reflection with the one without
+func BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Even(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any { return bridge.Even }, `0`)
+	})
+	b.Run("fast", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any {
+			return bridge.fastEven
+		}, `0`)
+	})
+}
+
+// BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Repeat compares the call of Repeat by reflection with the one without
+func BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Repeat(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any { return bridge.Repeat }, `"", 0`)
+	})
+	b.Run("fast", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any {
+			return bridge.fastRepeat
+		}, `"", 0`)
+	})
+}
+
+// BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Scale compares the call of Scale by reflection with the one without
+func BenchmarkGoja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls_Scale(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any { return bridge.Scale }, `0, 0`)
+	})
+	b.Run("fast", func(b *testing.B) {
+		bridgeBenchCall(b, func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any {
+			return bridge.fastScale
+		}, `0, 0`)
+	})
+}
+
+// bridgeBenchCall calls the function returned by fn from JavaScript with args b.N times
+func bridgeBenchCall(b *testing.B, fn func(bridge *Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls) any, args string) {
+	vm := goja.New()
+
+	err := vm.Set("f", fn(&Goja_go_github_com_mpetavy_goja_go_runtime_internal_fastcalls{vm: vm}))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	v, err := vm.RunString("(function() { return f(" + args + ") })")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	call, ok := goja.AssertFunction(v)
+	if !ok {
+		b.Fatal("not a function")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				b.Skipf("f(%s) panics: %v", args, r)
+			}
+		}()
+
+		_, err = call(goja.Undefined())
+	}()
+
+	if err != nil {
+		b.Skipf("f(%s) throws: %v", args, err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = call(goja.Undefined())
+	}
+}
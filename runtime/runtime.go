@@ -0,0 +1,137 @@
+// Package runtime provides the conversions between goja and Go shared by the bridges which
+// goja_go generates with -shared-runtime instead of generating them into each bridge.
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+
+	"github.com/dop251/goja"
+)
+
+// PackageIsVersion1 is referenced by the generated bridges requiring this version of the package,
+// so they fail to compile against an incompatible one
+const PackageIsVersion1 = true
+
+var ErrInterrupted = errors.New("script interrupted")
+
+// Arg returns the i-th argument of call, nil if it is missing like goja passes it by reflection
+func Arg(call goja.FunctionCall, i int) goja.Value {
+	if i >= len(call.Arguments) {
+		return nil
+	}
+
+	return call.Arguments[i]
+}
+
+func ArgString(call goja.FunctionCall, i int) string {
+	if i >= len(call.Arguments) {
+		return ""
+	}
+
+	return call.Arguments[i].String()
+}
+
+// ArgFloat returns the i-th argument of call as float, 0 if it is missing instead of NaN for undefined
+func ArgFloat(call goja.FunctionCall, i int) float64 {
+	if i >= len(call.Arguments) {
+		return 0
+	}
+
+	return call.Arguments[i].ToFloat()
+}
+
+// Result returns undefined for a nil result like goja does for results returned by reflection
+func Result(v goja.Value) goja.Value {
+	if v == nil {
+		return goja.Undefined()
+	}
+
+	return v
+}
+
+// Interrupt interrupts vm once interrupt is closed, aborting the bridged call by an
+// exception the runtime follows by the uncatchable interruption
+func Interrupt(vm *goja.Runtime, interrupt <-chan struct{}) {
+	select {
+	case <-interrupt:
+		vm.Interrupt(ErrInterrupted)
+
+		panic(vm.NewGoError(ErrInterrupted))
+	default:
+	}
+}
+
+// Recover converts a panic of a bridged function into a JS exception, it must be deferred
+func Recover(vm *goja.Runtime) {
+	r := recover()
+
+	switch r.(type) {
+	case nil:
+		return
+	case *goja.Exception, *goja.InterruptedError, goja.Value:
+		panic(r)
+	}
+
+	panic(vm.NewGoError(fmt.Errorf("panic: %v\n%s", r, debug.Stack())))
+}
+
+func Assign(vm *goja.Runtime, target goja.Value, v interface{}) {
+	obj, ok := target.(*goja.Object)
+	if !ok {
+		return
+	}
+
+	src, ok := vm.ToValue(v).(*goja.Object)
+	if !ok {
+		return
+	}
+
+	for _, key := range src.Keys() {
+		_ = obj.Set(key, src.Get(key))
+	}
+}
+
+func Export(vm *goja.Runtime, v goja.Value, target interface{}) {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return
+	}
+
+	if obj, ok := v.(*goja.Object); ok {
+		if inner := obj.Get("__value"); inner != nil {
+			rv := reflect.ValueOf(inner.Export())
+			rt := reflect.ValueOf(target).Elem()
+
+			switch {
+			case rv.Type().AssignableTo(rt.Type()):
+				rt.Set(rv)
+
+				return
+			case rv.Kind() == reflect.Pointer && rv.Elem().Type().AssignableTo(rt.Type()):
+				rt.Set(rv.Elem())
+
+				return
+			}
+		}
+	}
+
+	err := vm.ExportTo(v, target)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+}
+
+func Null(vm *goja.Runtime, v interface{}) goja.Value {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Interface, reflect.Chan:
+		if rv.IsNil() {
+			return goja.Null()
+		}
+	}
+
+	return vm.ToValue(v)
+}
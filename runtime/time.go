@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+var isoPattern = regexp.MustCompile(`^(-)?P(?:([\d.]+)W)?(?:([\d.]+)D)?(?:T(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?)?$`)
+
+// Duration converts JS milliseconds, a Go duration string or an ISO-8601 duration to a time.Duration
+func Duration(vm *goja.Runtime, v goja.Value) time.Duration {
+	if s, ok := v.Export().(string); ok {
+		if isoPattern.MatchString(s) {
+			return parseISODuration(vm, s)
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+
+		return d
+	}
+
+	return time.Duration(v.ToFloat() * float64(time.Millisecond))
+}
+
+func parseISODuration(vm *goja.Runtime, s string) time.Duration {
+	match := isoPattern.FindStringSubmatch(s)
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(match[i+2], 64)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("invalid ISO-8601 duration: %s", s)))
+		}
+
+		d += time.Duration(f * float64(unit))
+	}
+
+	if match[1] != "" {
+		d = -d
+	}
+
+	return d
+}
+
+func ISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	s := "PT"
+	if d < 0 {
+		s = "-PT"
+		d = -d
+	}
+
+	if h := d / time.Hour; h > 0 {
+		s += fmt.Sprintf("%dH", h)
+		d -= h * time.Hour
+	}
+
+	if m := d / time.Minute; m > 0 {
+		s += fmt.Sprintf("%dM", m)
+		d -= m * time.Minute
+	}
+
+	if d > 0 {
+		s += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+
+	return s
+}
+
+func Time(vm *goja.Runtime, v goja.Value) time.Time {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return time.Time{}
+	}
+
+	switch x := v.Export().(type) {
+	case time.Time:
+		return x
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, x)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+
+		return t
+	}
+
+	return time.UnixMilli(v.ToInteger())
+}
+
+func Date(vm *goja.Runtime, t time.Time) goja.Value {
+	if t.IsZero() {
+		return goja.Null()
+	}
+
+	date, err := vm.New(vm.Get("Date"), vm.ToValue(t.UnixMilli()))
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+
+	return date
+}